@@ -0,0 +1,87 @@
+package stream2sentence
+
+// AdaptiveConfig lets the splitter trade latency for longer sentences while
+// its consumer lags behind. The MinimumSentenceLength and QuickYieldMode of
+// the splitter configuration apply while the consumer is idle. As its
+// backlog grows towards HighWater, the minimum sentence length rises
+// linearly to the lagging bound, and the quick yield mode switches to the
+// lagging one at LaggingQuickYieldDepth. Longer sentences mean fewer TTS
+// requests and better prosody, short fragments mean a quick start when the
+// consumer is waiting.
+type AdaptiveConfig struct {
+	// HighWater is the backlog, in sentences, at which the lagging minimum
+	// sentence length applies fully. Adaptation is off while it is zero.
+	HighWater int
+
+	// LaggingMinimumSentenceLength is the minimum sentence length at
+	// HighWater. It must not be below MinimumSentenceLength.
+	LaggingMinimumSentenceLength int
+
+	// LaggingQuickYieldMode, if set, replaces QuickYieldMode once the
+	// backlog reaches LaggingQuickYieldDepth. If nil, the quick yield mode
+	// is not adapted.
+	LaggingQuickYieldMode *QuickYieldMode
+
+	// LaggingQuickYieldDepth is the backlog at which LaggingQuickYieldMode
+	// applies, HighWater if zero
+	LaggingQuickYieldDepth int
+}
+
+// quickYieldDepth returns the backlog at which the lagging quick yield mode
+// applies
+func (c AdaptiveConfig) quickYieldDepth() int {
+	if c.LaggingQuickYieldDepth <= 0 {
+		return c.HighWater
+	}
+	return c.LaggingQuickYieldDepth
+}
+
+// ReportQueueDepth tells the splitter how many sentences its consumer has
+// queued but not yet spoken, e.g. the length of a TTS queue. Sentences
+// unread in the channel of Stream, Flush or a generator are counted on top.
+// The new depth takes effect with the next chunk. ReportQueueDepth may be
+// called from any goroutine and does nothing unless AdaptiveConfig.HighWater
+// is set.
+func (s *SentenceSplitter) ReportQueueDepth(depth int) {
+	s.reportedDepth.Store(int64(max(depth, 0)))
+}
+
+// ReportIdle tells the splitter that its consumer has nothing left to say,
+// like ReportQueueDepth(0)
+func (s *SentenceSplitter) ReportIdle() {
+	s.ReportQueueDepth(0)
+}
+
+// measureBacklog sets the function counting the sentences emitted but not
+// yet read by the consumer
+func (s *SentenceSplitter) measureBacklog(backlog func() int) {
+	s.signalMu.Lock()
+	defer s.signalMu.Unlock()
+	s.backlog = backlog
+}
+
+// adapt moves the minimum sentence length and quick yield mode between the
+// idle and lagging bounds according to the consumer's backlog
+func (s *SentenceSplitter) adapt() {
+	highWater := s.adaptive.HighWater
+	if highWater <= 0 {
+		return
+	}
+
+	depth := int(s.reportedDepth.Load())
+	s.signalMu.Lock()
+	if s.backlog != nil {
+		depth += s.backlog()
+	}
+	s.signalMu.Unlock()
+	depth = min(depth, highWater)
+
+	// Integer division rounds towards the idle length
+	s.minimumSentenceLength = s.idleMinimumSentenceLength +
+		(s.adaptive.LaggingMinimumSentenceLength-s.idleMinimumSentenceLength)*depth/highWater
+
+	s.quickYieldMode = s.idleQuickYieldMode
+	if s.laggingQuickYield && depth >= s.adaptive.quickYieldDepth() {
+		s.quickYieldMode = s.laggingQuickYieldMode
+	}
+}
@@ -0,0 +1,201 @@
+package stream2sentence
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adaptiveConfig moves from quick fragments of 10 characters when idle to
+// sentences of at least 50 characters without quick yields at a backlog of 4
+func adaptiveConfig() SentenceSplitterConfig {
+	noQuickYield := NoQuickYield
+
+	config := DefaultConfig()
+	config.MinimumSentenceLength = 10
+	config.QuickYieldMode = QuickYieldAllFragments
+	config.Adaptive = AdaptiveConfig{
+		HighWater:                    4,
+		LaggingMinimumSentenceLength: 50,
+		LaggingQuickYieldMode:        &noQuickYield,
+	}
+	return config
+}
+
+// === Adaptive Tuning Tests ===
+
+func TestAdaptBounds(t *testing.T) {
+	splitter := NewSentenceSplitter(adaptiveConfig())
+
+	tests := []struct {
+		depth          int
+		minimumLength  int
+		quickYieldMode QuickYieldMode
+	}{
+		{0, 10, QuickYieldAllFragments},
+		{1, 20, QuickYieldAllFragments},
+		{2, 30, QuickYieldAllFragments},
+		{3, 40, QuickYieldAllFragments},
+		{4, 50, NoQuickYield},
+		{12, 50, NoQuickYield},
+		{-3, 10, QuickYieldAllFragments},
+	}
+	for _, tt := range tests {
+		splitter.ReportQueueDepth(tt.depth)
+		splitter.adapt()
+		assert.Equal(t, tt.minimumLength, splitter.minimumSentenceLength, "depth %d", tt.depth)
+		assert.Equal(t, tt.quickYieldMode, splitter.quickYieldMode, "depth %d", tt.depth)
+	}
+
+	splitter.ReportIdle()
+	splitter.adapt()
+	assert.Equal(t, 10, splitter.minimumSentenceLength)
+}
+
+func TestAdaptQuickYieldDepth(t *testing.T) {
+	firstFragment := QuickYieldFirstFragment
+	config := adaptiveConfig()
+	config.Adaptive.LaggingQuickYieldMode = &firstFragment
+	config.Adaptive.LaggingQuickYieldDepth = 2
+	splitter := NewSentenceSplitter(config)
+
+	// The mode switches at the threshold, with no intermediate steps
+	modes := []QuickYieldMode{QuickYieldAllFragments, QuickYieldAllFragments, QuickYieldFirstFragment, QuickYieldFirstFragment}
+	for depth, mode := range modes {
+		splitter.ReportQueueDepth(depth)
+		splitter.adapt()
+		assert.Equal(t, mode, splitter.quickYieldMode, "depth %d", depth)
+	}
+
+	// Changes through the pointer after construction are ignored
+	firstFragment = NoQuickYield
+	splitter.adapt()
+	assert.Equal(t, QuickYieldFirstFragment, splitter.quickYieldMode)
+}
+
+func TestAdaptQuickYieldUnset(t *testing.T) {
+	config := adaptiveConfig()
+	config.Adaptive.LaggingQuickYieldMode = nil
+	splitter := NewSentenceSplitter(config)
+
+	splitter.ReportQueueDepth(4)
+	splitter.adapt()
+	assert.Equal(t, 50, splitter.minimumSentenceLength)
+	assert.Equal(t, QuickYieldAllFragments, splitter.quickYieldMode)
+}
+
+func TestAdaptDisabled(t *testing.T) {
+	config := adaptiveConfig()
+	config.Adaptive.HighWater = 0
+	splitter := NewSentenceSplitter(config)
+
+	splitter.ReportQueueDepth(10)
+	splitter.adapt()
+	assert.Equal(t, 10, splitter.minimumSentenceLength)
+	assert.Equal(t, QuickYieldAllFragments, splitter.quickYieldMode)
+}
+
+func TestAdaptSentenceLength(t *testing.T) {
+	text := "Sure, I can help. First, open the app. Then tap on settings. Finally, choose your plan."
+
+	idle := NewSentenceSplitter(adaptiveConfig())
+	idle.ReportIdle()
+	idle.Add(text)
+	short := drain(idle, true)
+
+	lagging := NewSentenceSplitter(adaptiveConfig())
+	lagging.ReportQueueDepth(4)
+	lagging.Add(text)
+	long := drain(lagging, true)
+
+	assert.Greater(t, len(short), len(long))
+	assert.Equal(t, words(text), words(strings.Join(short, " ")))
+	assert.Equal(t, words(text), words(strings.Join(long, " ")))
+	for _, sentence := range long[:len(long)-1] {
+		assert.GreaterOrEqual(t, len(sentence), 50, sentence)
+	}
+}
+
+func TestAdaptChannelBacklog(t *testing.T) {
+	config := adaptiveConfig()
+	config.QuickYieldMode = NoQuickYield
+	splitter := NewSentenceSplitter(config)
+
+	// Sentences left unread in the channel count towards the backlog
+	results := make(chan string, 10)
+	splitter.track(results)
+	splitter.Add("One sentence here. Two sentences here. Three sentences here. And")
+	require.NoError(t, splitter.process(splitter.sendTo(results)))
+	require.Len(t, results, 2)
+
+	splitter.Add(" more")
+	require.NoError(t, splitter.process(splitter.sendTo(results)))
+	assert.Equal(t, 30, splitter.minimumSentenceLength)
+
+	// The consumer's own queue is added on top
+	splitter.ReportQueueDepth(2)
+	splitter.Add(" text")
+	require.NoError(t, splitter.process(splitter.sendTo(results)))
+	assert.Equal(t, 50, splitter.minimumSentenceLength)
+}
+
+func TestAdaptGenerationBacklog(t *testing.T) {
+	generator := make(chan string, 1)
+	generator <- "The first sentence is here. The second sentence is here. The third one is still coming"
+	generation := GenerateSentencesTracked(context.Background(), generator, GenerateSentencesConfig{SentenceSplitterConfig: adaptiveConfig()})
+
+	first := <-generation.Sentences()
+	second := <-generation.Sentences()
+	generation.Ack(first)
+
+	generation.splitter.signalMu.Lock()
+	backlog := generation.splitter.backlog()
+	generation.splitter.signalMu.Unlock()
+	assert.Equal(t, 1, backlog, "second sentence %q not acknowledged", second.Text)
+
+	generation.Interrupt()
+}
+
+func TestAdaptSnapshot(t *testing.T) {
+	splitter := NewSentenceSplitter(adaptiveConfig())
+	splitter.ReportQueueDepth(4)
+	splitter.adapt()
+
+	// Snapshots keep the configured values, not the adapted ones
+	data, err := splitter.Snapshot()
+	require.NoError(t, err)
+
+	restored := NewSentenceSplitter(DefaultConfig())
+	require.NoError(t, restored.Restore(data))
+	assert.Equal(t, 10, restored.minimumSentenceLength)
+	assert.Equal(t, QuickYieldAllFragments, restored.quickYieldMode)
+}
+
+func TestValidateAdaptive(t *testing.T) {
+	unknown := QuickYieldMode(9)
+	config := DefaultConfig()
+	config.Adaptive = AdaptiveConfig{HighWater: -1, LaggingMinimumSentenceLength: -1, LaggingQuickYieldMode: &unknown, LaggingQuickYieldDepth: 1}
+
+	err := config.Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, []string{
+		"Adaptive.HighWater",
+		"Adaptive.LaggingMinimumSentenceLength",
+		"Adaptive.LaggingQuickYieldMode",
+		"Adaptive.LaggingQuickYieldDepth",
+	}, configErrorFields(err))
+
+	// The lagging length must not undercut the idle one
+	config = adaptiveConfig()
+	config.Adaptive.LaggingMinimumSentenceLength = 5
+	assert.Equal(t, []string{"Adaptive.LaggingMinimumSentenceLength"}, configErrorFields(config.Validate()))
+
+	// A disabled zero value is valid
+	config = DefaultConfig()
+	config.Adaptive = AdaptiveConfig{}
+	assert.NoError(t, config.Validate())
+	assert.NoError(t, adaptiveConfig().Validate())
+}
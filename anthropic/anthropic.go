@@ -0,0 +1,135 @@
+// Package anthropic reads Anthropic Messages API streams, the Server-Sent
+// Events returned with "stream": true, as a stream2sentence chunk source
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/sse"
+)
+
+// ErrTruncated is returned when a stream ends without a message_stop event
+var ErrTruncated = errors.New("anthropic: stream ended without message_stop")
+
+// APIError is an error event reported inside the stream, e.g. when the API
+// is overloaded
+type APIError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("anthropic: %s: %s", e.Type, e.Message)
+}
+
+// Config holds the options of a Source
+type Config struct {
+	// OnStop, if set, is called with the stop reason of the message, e.g.
+	// "end_turn", "max_tokens" or "tool_use"
+	OnStop func(reason string)
+}
+
+// event is the payload of a stream event. Only the fields needed to
+// extract text are decoded.
+type event struct {
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	ContentBlock struct {
+		Type string `json:"type"`
+	} `json:"content_block"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Error *APIError `json:"error"`
+}
+
+// Source reads the text of a Messages stream. Only text deltas are returned;
+// thinking, signature and tool input deltas are skipped. The end of every
+// text content block is reported as stream2sentence.ErrBoundary, so text
+// from separate blocks never ends up in the same sentence. Source
+// implements stream2sentence.Source.
+type Source struct {
+	events *sse.Reader
+	config Config
+	done   bool
+
+	// text reports whether the current content block produced text
+	text bool
+}
+
+var _ stream2sentence.Source = (*Source)(nil)
+
+// NewSource returns a Source reading a Messages stream from r, e.g. the body
+// of a streaming HTTP response
+func NewSource(r io.Reader, config Config) *Source {
+	return &Source{events: sse.NewReader(r), config: config}
+}
+
+// Next returns the next text delta. It returns stream2sentence.ErrBoundary
+// at the end of a text content block, io.EOF after message_stop,
+// ErrTruncated if the stream ends before it and an *APIError for error
+// events. Reads from the underlying reader are not interrupted by ctx;
+// cancel the HTTP request to abort a blocked read.
+func (s *Source) Next(ctx context.Context) (string, error) {
+	for {
+		if s.done {
+			return "", io.EOF
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		sseEvent, err := s.events.Next()
+		if errors.Is(err, io.EOF) {
+			return "", ErrTruncated
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var e event
+		if err := json.Unmarshal([]byte(sseEvent.Data), &e); err != nil {
+			return "", fmt.Errorf("anthropic: invalid %s event: %w", sseEvent.Type, err)
+		}
+
+		switch e.Type {
+		case "content_block_start":
+			s.text = false
+
+		case "content_block_delta":
+			if e.Delta.Type == "text_delta" && e.Delta.Text != "" {
+				s.text = true
+				return e.Delta.Text, nil
+			}
+
+		case "content_block_stop":
+			if s.text {
+				s.text = false
+				return "", stream2sentence.ErrBoundary
+			}
+
+		case "message_delta":
+			if e.Delta.StopReason != "" && s.config.OnStop != nil {
+				s.config.OnStop(e.Delta.StopReason)
+			}
+
+		case "message_stop":
+			s.done = true
+			return "", io.EOF
+
+		case "error":
+			if e.Error == nil {
+				return "", &APIError{Type: "error", Message: sseEvent.Data}
+			}
+			return "", e.Error
+		}
+	}
+}
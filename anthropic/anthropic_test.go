@@ -0,0 +1,134 @@
+package anthropic
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// openFixture opens a recorded stream from testdata
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// readResults reads chunks until the stream ends, recording boundaries as "|"
+func readResults(source *Source) ([]string, error) {
+	var results []string
+	for {
+		chunk, err := source.Next(context.Background())
+		switch err {
+		case nil:
+			results = append(results, chunk)
+		case stream2sentence.ErrBoundary:
+			results = append(results, "|")
+		case io.EOF:
+			return results, nil
+		default:
+			return results, err
+		}
+	}
+}
+
+// collectSentences splits the stream of a fixture into sentences
+func collectSentences(t *testing.T, fixture string) ([]string, error) {
+	t.Helper()
+
+	source := NewSource(openFixture(t, fixture), Config{})
+	config := stream2sentence.GenerateSentencesConfig{SentenceSplitterConfig: stream2sentence.DefaultConfig()}
+
+	var sentences []string
+	for sentence, err := range stream2sentence.SentencesFromSource(context.Background(), source, config) {
+		if err != nil {
+			return sentences, err
+		}
+		sentences = append(sentences, sentence.Text)
+	}
+	return sentences, nil
+}
+
+func TestSourceFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		results []string
+	}{
+		{
+			fixture: "text.sse",
+			results: []string{"Hello", "! The capital of France", " is Paris.", " It is known for the", " Eiffel Tower", "|"},
+		},
+		{
+			fixture: "multi_block.sse",
+			results: []string{"Let me look that up for you", "|"},
+		},
+		{
+			fixture: "text_blocks.sse",
+			results: []string{"According to the report", "|", "the sky is blue", " on clear days.", "|"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			var reasons []string
+			source := NewSource(openFixture(t, tt.fixture), Config{
+				OnStop: func(reason string) { reasons = append(reasons, reason) },
+			})
+
+			results, err := readResults(source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.results, results)
+			assert.Len(t, reasons, 1)
+		})
+	}
+}
+
+func TestSourceStopReason(t *testing.T) {
+	var reason string
+	source := NewSource(openFixture(t, "multi_block.sse"), Config{
+		OnStop: func(r string) { reason = r },
+	})
+
+	_, err := readResults(source)
+	require.NoError(t, err)
+	assert.Equal(t, "tool_use", reason)
+}
+
+func TestSourceErrorEvent(t *testing.T) {
+	results, err := readResults(NewSource(openFixture(t, "error.sse"), Config{}))
+	assert.Equal(t, []string{"This sentence is complete.", " This one is"}, results)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "overloaded_error", apiErr.Type)
+	assert.EqualError(t, err, "anthropic: overloaded_error: Overloaded")
+}
+
+func TestSourceTruncated(t *testing.T) {
+	results, err := readResults(NewSource(openFixture(t, "truncated.sse"), Config{}))
+	assert.Equal(t, []string{"Cut off", " mid"}, results)
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestSourceSentences(t *testing.T) {
+	sentences, err := collectSentences(t, "text_blocks.sse")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"According to the report", "the sky is blue on clear days."}, sentences)
+
+	sentences, err = collectSentences(t, "text.sse")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hello! The capital of France is Paris.", "It is known for the Eiffel Tower"}, sentences)
+
+	sentences, err = collectSentences(t, "error.sse")
+	assert.Equal(t, []string{"This sentence is complete."}, sentences)
+	assert.ErrorIs(t, err, stream2sentence.ErrUpstream)
+}
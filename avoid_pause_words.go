@@ -55,6 +55,11 @@ var subordinatingConjunctions = []string{
 // AvoidPauseWords contains all words that should be avoided for pausing
 var AvoidPauseWords map[string]bool
 
+func init() {
+	// Initialize eagerly so concurrent lookups do not race on the map
+	initAvoidPauseWords()
+}
+
 // initAvoidPauseWords initializes the AvoidPauseWords map
 func initAvoidPauseWords() {
 	if AvoidPauseWords != nil {
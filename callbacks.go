@@ -0,0 +1,93 @@
+package stream2sentence
+
+import "context"
+
+// Sentence is a unit of text emitted by the splitter
+type Sentence struct {
+	// Text is the cleaned sentence text
+	Text string
+
+	// Index is the position of the sentence in the stream, starting at 0
+	Index int
+
+	// Fragment reports whether the text was quick-yielded at a fragment
+	// delimiter rather than at a confirmed sentence boundary
+	Fragment bool
+}
+
+// Callbacks holds optional functions the splitter invokes synchronously as
+// it processes text. If a callback returns an error, processing stops and
+// the error is returned by Feed, Finish and Err. Sentences still reach the
+// channels returned by Stream and Flush, which close early on error.
+type Callbacks struct {
+	// OnSentence is called for every emitted sentence
+	OnSentence func(Sentence) error
+
+	// OnFragment, if set, is called instead of OnSentence for quick-yielded
+	// fragments
+	OnFragment func(Sentence) error
+
+	// OnCharacter is called for every character as it is consumed, e.g. to
+	// echo streaming text to a UI in lockstep with sentence emission
+	OnCharacter func(rune) error
+
+	// OnFinish is called once the remaining buffer has been flushed by Finish
+	OnFinish func() error
+}
+
+// Feed adds a text chunk and processes it, invoking the configured callbacks.
+// It returns ErrInterrupted if Interrupt stops it; the unprocessed text is
+// part of the Remainder returned by Interrupt.
+func (s *SentenceSplitter) Feed(chunk string) error {
+	s.Add(chunk)
+	return s.process(nil)
+}
+
+// Finish flushes the remaining buffer through the configured callbacks and
+// calls OnFinish. It returns ErrInterrupted, without calling OnFinish, if
+// Interrupt stops it.
+func (s *SentenceSplitter) Finish() error {
+	if err := s.flush(nil); err != nil {
+		return err
+	}
+
+	if s.callbacks.OnFinish != nil {
+		if err := s.callbacks.OnFinish(); err != nil {
+			s.err = err
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Err returns the error that stopped processing, if any
+func (s *SentenceSplitter) Err() error {
+	if s.err == nil && s.closed() {
+		return ErrClosed
+	}
+	return s.err
+}
+
+// ProcessSentences feeds every chunk from generator through a splitter that
+// reports sentences via config.Callbacks. It runs on the calling goroutine and
+// returns once the generator is closed and flushed, when ctx is done or when
+// a callback fails.
+func ProcessSentences(ctx context.Context, generator <-chan string, config GenerateSentencesConfig) error {
+	splitter := NewSentenceSplitter(config.SentenceSplitterConfig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case chunk, ok := <-generator:
+			if !ok {
+				return splitter.Finish()
+			}
+
+			if err := splitter.Feed(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
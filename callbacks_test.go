@@ -0,0 +1,150 @@
+package stream2sentence
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbacks(t *testing.T) {
+	var (
+		sentences []Sentence
+		fragments []Sentence
+		echoed    strings.Builder
+		finished  int
+	)
+
+	config := DefaultConfig()
+	config.Callbacks = Callbacks{
+		OnSentence: func(sentence Sentence) error {
+			sentences = append(sentences, sentence)
+			return nil
+		},
+		OnFragment: func(sentence Sentence) error {
+			fragments = append(fragments, sentence)
+			return nil
+		},
+		OnCharacter: func(char rune) error {
+			echoed.WriteRune(char)
+			return nil
+		},
+		OnFinish: func() error {
+			finished++
+			return nil
+		},
+	}
+
+	text := "Hello there, this is a test. Another sentence follows here. Done."
+	splitter := NewSentenceSplitter(config)
+	for _, chunk := range splitRunes(text, 4) {
+		require.NoError(t, splitter.Feed(chunk))
+	}
+	require.NoError(t, splitter.Finish())
+
+	assert.Equal(t, text, echoed.String())
+	assert.Equal(t, 1, finished)
+	assert.Equal(t, []Sentence{{Text: "Hello there,", Index: 0, Fragment: true}}, fragments)
+	assert.Equal(t, []Sentence{
+		{Text: "this is a test.", Index: 1},
+		{Text: "Another sentence follows here.", Index: 2},
+		{Text: "Done.", Index: 3},
+	}, sentences)
+}
+
+func TestCallbacksFragmentFallback(t *testing.T) {
+	var sentences []Sentence
+
+	config := DefaultConfig()
+	config.Callbacks.OnSentence = func(sentence Sentence) error {
+		sentences = append(sentences, sentence)
+		return nil
+	}
+
+	splitter := NewSentenceSplitter(config)
+	require.NoError(t, splitter.Feed("Hello there, this is a test."))
+	require.NoError(t, splitter.Finish())
+
+	require.Len(t, sentences, 2)
+	assert.True(t, sentences[0].Fragment)
+	assert.False(t, sentences[1].Fragment)
+}
+
+func TestCallbackErrorAbortsProcessing(t *testing.T) {
+	errStop := errors.New("stop")
+
+	var calls int
+	config := DefaultConfig()
+	config.Callbacks.OnSentence = func(Sentence) error {
+		calls++
+		return errStop
+	}
+
+	splitter := NewSentenceSplitter(config)
+	err := splitter.Feed("First sentence is here. Second sentence is here. Third sentence is here.")
+	assert.ErrorIs(t, err, errStop)
+	assert.ErrorIs(t, splitter.Err(), errStop)
+	assert.ErrorIs(t, splitter.Feed("More text."), errStop)
+	assert.ErrorIs(t, splitter.Finish(), errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestCallbackErrorStopsGenerateSentences(t *testing.T) {
+	config := DefaultConfig()
+	config.Callbacks.OnCharacter = func(char rune) error {
+		if char == '!' {
+			return errors.New("interrupted")
+		}
+		return nil
+	}
+
+	sentences := collectSentences(GenerateSentencesFromSlice(
+		[]string{"This is the first sentence. ", "Stop right here! ", "This is never emitted."},
+		GenerateSentencesConfig{SentenceSplitterConfig: config},
+	))
+
+	assert.Equal(t, []string{"This is the first sentence."}, sentences)
+}
+
+func TestProcessSentences(t *testing.T) {
+	var sentences []string
+
+	config := DefaultConfig()
+	config.Callbacks.OnSentence = func(sentence Sentence) error {
+		sentences = append(sentences, sentence.Text)
+		return nil
+	}
+
+	text := "Character by character. This should work perfectly!"
+	err := ProcessSentences(context.Background(), createCharacterGenerator(text), GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Character by character.", "This should work perfectly!"}, sentences)
+}
+
+func TestProcessSentencesErrors(t *testing.T) {
+	errStop := errors.New("stop")
+
+	config := DefaultConfig()
+	config.Callbacks.OnFinish = func() error {
+		return errStop
+	}
+
+	err := ProcessSentences(context.Background(), createWordGenerator("Just one sentence."), GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = ProcessSentences(ctx, make(chan string), GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,340 @@
+// Package caption segments a stream2sentence sentence stream into caption
+// cues that respect per-line character and per-cue line limits
+package caption
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// DefaultWordsPerMinute is a typical speaking rate of synthesized speech
+const DefaultWordsPerMinute = 150
+
+// Config holds the caption layout and timing options. Zero values are
+// replaced by the defaults of DefaultConfig.
+type Config struct {
+	// Splitter configures the sentence splitter feeding the captioner
+	Splitter stream2sentence.SentenceSplitterConfig
+
+	// MaxLineLength is the maximum number of characters per line. Words
+	// longer than this are put on a line of their own and never broken.
+	MaxLineLength int
+
+	// MaxLines is the maximum number of lines per cue
+	MaxLines int
+
+	// CharactersPerSecond is the reading speed used to time cues
+	CharactersPerSecond float64
+
+	// MinDuration and MaxDuration bound the display time of a cue
+	MinDuration time.Duration
+	MaxDuration time.Duration
+}
+
+// DefaultConfig returns the default caption configuration: two lines of at
+// most 42 characters, read at 17 characters per second
+func DefaultConfig() Config {
+	return Config{
+		Splitter:            stream2sentence.CaptionConfig(),
+		MaxLineLength:       42,
+		MaxLines:            2,
+		CharactersPerSecond: 17,
+		MinDuration:         time.Second,
+		MaxDuration:         7 * time.Second,
+	}
+}
+
+// withDefaults returns a copy of the configuration with unset fields filled
+// in from DefaultConfig
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+
+	c.Splitter = c.Splitter.WithDefaultsFrom(defaults.Splitter)
+	if c.MaxLineLength <= 0 {
+		c.MaxLineLength = defaults.MaxLineLength
+	}
+	if c.MaxLines <= 0 {
+		c.MaxLines = defaults.MaxLines
+	}
+	if c.CharactersPerSecond <= 0 {
+		c.CharactersPerSecond = defaults.CharactersPerSecond
+	}
+	if c.MinDuration <= 0 {
+		c.MinDuration = defaults.MinDuration
+	}
+	if c.MaxDuration <= 0 {
+		c.MaxDuration = defaults.MaxDuration
+	}
+	c.MaxDuration = max(c.MaxDuration, c.MinDuration)
+
+	return c
+}
+
+// Cue is a caption shown on screen for a time span
+type Cue struct {
+	// Index is the position of the cue in the stream, starting at 0
+	Index int
+
+	// Lines holds the text of the cue, one entry per line
+	Lines []string
+
+	// Start and End are the display times relative to the start of the stream
+	Start time.Duration
+	End   time.Duration
+}
+
+// Text returns the lines of the cue joined by newlines
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, "\n")
+}
+
+// Segmenter lays out sentences as timed cues. Cues are timed by reading
+// speed and never overlap: each starts when its sentence arrived or when
+// the previous cue ends, whichever is later.
+type Segmenter struct {
+	config Config
+	index  int
+	end    time.Duration
+}
+
+// NewSegmenter creates a Segmenter from the given configuration
+func NewSegmenter(config Config) *Segmenter {
+	return &Segmenter{config: config.withDefaults()}
+}
+
+// Segment lays out a sentence that arrived at the given offset from the
+// start of the stream. Pass an arrival of 0 to lay out cues back to back.
+func (s *Segmenter) Segment(sentence string, arrival time.Duration) []Cue {
+	var cues []Cue
+
+	for _, lines := range Wrap(sentence, s.config.MaxLineLength, s.config.MaxLines) {
+		characters := 0
+		for _, line := range lines {
+			characters += utf8.RuneCountInString(line)
+		}
+
+		duration := time.Duration(float64(characters) / s.config.CharactersPerSecond * float64(time.Second))
+		duration = min(max(duration, s.config.MinDuration), s.config.MaxDuration)
+
+		start := max(arrival, s.end)
+		s.end = start + duration
+
+		cues = append(cues, Cue{
+			Index: s.index,
+			Lines: lines,
+			Start: start,
+			End:   s.end,
+		})
+		s.index++
+	}
+
+	return cues
+}
+
+// EstimateDuration estimates how long text takes to speak at the given
+// number of words per minute, or DefaultWordsPerMinute if it is not positive
+func EstimateDuration(text string, wordsPerMinute float64) time.Duration {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = DefaultWordsPerMinute
+	}
+
+	words := len(strings.Fields(text))
+	return time.Duration(float64(words) / wordsPerMinute * float64(time.Minute))
+}
+
+// SegmentSpoken lays out a sentence that is spoken for the given duration,
+// right after the previously spoken sentence ends. The duration, e.g. the
+// length of the synthesized audio or an EstimateDuration, is shared between
+// the sentence's cues by length, so captions follow the speech. The reading
+// speed limits of the configuration do not apply.
+func (s *Segmenter) SegmentSpoken(sentence string, duration time.Duration) []Cue {
+	duration = max(duration, 0)
+	wrapped := Wrap(sentence, s.config.MaxLineLength, s.config.MaxLines)
+
+	total := 0
+	lengths := make([]int, len(wrapped))
+	for i, lines := range wrapped {
+		for _, line := range lines {
+			lengths[i] += utf8.RuneCountInString(line)
+		}
+		total += lengths[i]
+	}
+
+	var cues []Cue
+	origin, spoken := s.end, 0
+	for i, lines := range wrapped {
+		spoken += lengths[i]
+		end := origin + time.Duration(float64(duration)*float64(spoken)/float64(total))
+
+		cues = append(cues, Cue{
+			Index: s.index,
+			Lines: lines,
+			Start: s.end,
+			End:   end,
+		})
+		s.index++
+		s.end = end
+	}
+
+	// Silence such as an empty sentence still advances the timeline
+	if len(cues) == 0 {
+		s.end += duration
+	}
+
+	return cues
+}
+
+// Wrap breaks text into cues of at most maxLines lines of at most
+// maxLineLength characters, breaking only between words. A line does not
+// end on a word that should not be followed by a pause, such as an article
+// or preposition, and a single word is never left alone on the last line
+// if it can be avoided.
+func Wrap(text string, maxLineLength, maxLines int) [][]string {
+	lines := wrapLines(strings.Fields(text), max(maxLineLength, 1))
+	maxLines = max(maxLines, 1)
+
+	var cues [][]string
+	for len(lines) > 0 {
+		n := min(maxLines, len(lines))
+		cues = append(cues, lines[:n])
+		lines = lines[n:]
+	}
+	return cues
+}
+
+// wrapLines fills lines greedily with words
+func wrapLines(words []string, maxLineLength int) []string {
+	var lines [][]string
+
+	for len(words) > 0 {
+		n, length := 1, utf8.RuneCountInString(words[0])
+		for n < len(words) {
+			next := length + 1 + utf8.RuneCountInString(words[n])
+			if next > maxLineLength {
+				break
+			}
+			n, length = n+1, next
+		}
+
+		// Carry trailing words that bind to what follows over to the next line
+		if n < len(words) {
+			for n > 1 && bindsForward(words[n-1]) {
+				n--
+			}
+		}
+
+		lines = append(lines, words[:n])
+		words = words[n:]
+	}
+
+	// Pull words down to keep a single word off the last line, preferring a
+	// split that does not leave a binding word at the end of the line above.
+	// The line above keeps at least two words, so it is not orphaned instead.
+	if last := len(lines) - 1; last > 0 && len(lines[last]) == 1 && len(lines[last-1]) > 2 {
+		previous := lines[last-1]
+		k := 0
+		for n := 1; n <= len(previous)-2; n++ {
+			moved := append(slices.Clone(previous[len(previous)-n:]), lines[last]...)
+			if utf8.RuneCountInString(strings.Join(moved, " ")) > maxLineLength {
+				break
+			}
+			if k == 0 || !bindsForward(previous[len(previous)-n-1]) {
+				k = n
+			}
+			if !bindsForward(previous[len(previous)-n-1]) {
+				break
+			}
+		}
+
+		if k > 0 {
+			lines[last] = append(slices.Clone(previous[len(previous)-k:]), lines[last]...)
+			lines[last-1] = previous[:len(previous)-k]
+		}
+	}
+
+	joined := make([]string, len(lines))
+	for i, line := range lines {
+		joined[i] = strings.Join(line, " ")
+	}
+	return joined
+}
+
+// bindsForward reports whether a line should not end with word, because it
+// is an avoid-pause word not followed by punctuation
+func bindsForward(word string) bool {
+	last, _ := utf8.DecodeLastRuneInString(word)
+	if unicode.IsPunct(last) {
+		return false
+	}
+	return stream2sentence.IsAvoidPauseWord(word)
+}
+
+// GenerateCues splits the text from generator into sentences and lays them
+// out as cues timed from the moment each sentence is complete. A fragment
+// shorter than a line, such as a quick-yielded "Absolutely,", is held back
+// and shares a cue with the text that follows it. The returned channel is
+// closed once the generator is closed and flushed or ctx is done.
+func GenerateCues(ctx context.Context, generator <-chan string, config Config) <-chan Cue {
+	config = config.withDefaults()
+	segmenter := NewSegmenter(config)
+	sentences := stream2sentence.GenerateSentencesAsync(ctx, generator, stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: config.Splitter,
+	})
+
+	cues := make(chan Cue)
+	go func() {
+		defer close(cues)
+
+		start := time.Now()
+		send := func(sentence string) bool {
+			for _, cue := range segmenter.Segment(sentence, time.Since(start)) {
+				select {
+				case cues <- cue:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		var pending string
+		for sentence := range sentences {
+			if pending != "" {
+				sentence = pending + " " + sentence
+				pending = ""
+			}
+
+			if utf8.RuneCountInString(sentence) < config.MaxLineLength &&
+				!endsSentence(sentence, config.Splitter.FullSentenceDelimiters) {
+				pending = sentence
+				continue
+			}
+
+			if !send(sentence) {
+				return
+			}
+		}
+
+		if pending != "" && ctx.Err() == nil {
+			send(pending)
+		}
+	}()
+
+	return cues
+}
+
+// endsSentence reports whether text ends with one of the full sentence
+// delimiters, ignoring closing quotes and brackets
+func endsSentence(text string, delimiters string) bool {
+	text = strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.In(r, unicode.Pe, unicode.Pf) || r == '"' || r == '\''
+	})
+	last, _ := utf8.DecodeLastRuneInString(text)
+	return text != "" && strings.ContainsRune(delimiters, last)
+}
@@ -0,0 +1,242 @@
+package caption
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// === Wrap Tests ===
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		length   int
+		lines    int
+		expected [][]string
+	}{
+		{
+			name:     "Fits on one line",
+			text:     "Hello there.",
+			length:   42,
+			lines:    2,
+			expected: [][]string{{"Hello there."}},
+		},
+		{
+			name:   "Two lines",
+			text:   "The quick brown fox jumps over the lazy dog near the river bank.",
+			length: 32,
+			lines:  2,
+			expected: [][]string{
+				{"The quick brown fox jumps", "over the lazy dog near"},
+				{"the river bank."},
+			},
+		},
+		{
+			name:   "Several cues",
+			text:   "One two three four five six seven eight nine ten eleven twelve.",
+			length: 15,
+			lines:  2,
+			expected: [][]string{
+				{"One two three", "four five six"},
+				{"seven eight", "nine ten"},
+				{"eleven twelve."},
+			},
+		},
+		{
+			name:   "Avoid-pause word carried over",
+			text:   "We walked along the shore in the light.",
+			length: 20,
+			lines:  3,
+			expected: [][]string{{
+				"We walked along",
+				"the shore",
+				"in the light.",
+			}},
+		},
+		{
+			name:   "No single word on the last line",
+			text:   "Captions should never leave words alone.",
+			length: 34,
+			lines:  2,
+			expected: [][]string{{
+				"Captions should never leave",
+				"words alone.",
+			}},
+		},
+		{
+			name:   "No single word left on the line above",
+			text:   "The quick brown fox dog and then runs into the forest quickly.",
+			length: 20,
+			lines:  2,
+			expected: [][]string{
+				{"The quick brown fox", "dog and then runs"},
+				{"into the", "forest quickly."},
+			},
+		},
+		{
+			name:   "Binding word kept rather than orphaning",
+			text:   "extraordinarily the uncharacteristically long.",
+			length: 42,
+			lines:  2,
+			expected: [][]string{{
+				"extraordinarily the",
+				"uncharacteristically long.",
+			}},
+		},
+		{
+			name:     "Overlong word kept whole",
+			text:     "Supercalifragilisticexpialidocious indeed.",
+			length:   10,
+			lines:    2,
+			expected: [][]string{{"Supercalifragilisticexpialidocious", "indeed."}},
+		},
+		{
+			name:     "Empty text",
+			text:     "  ",
+			length:   10,
+			lines:    2,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Wrap(tt.text, tt.length, tt.lines))
+		})
+	}
+}
+
+func TestWrapLimits(t *testing.T) {
+	text := strings.Repeat("Streaming captions are laid out at word boundaries, within limits. ", 5)
+
+	cues := Wrap(text, 30, 2)
+	require.NotEmpty(t, cues)
+
+	var words []string
+	for _, cue := range cues {
+		assert.LessOrEqual(t, len(cue), 2)
+		for _, line := range cue {
+			assert.LessOrEqual(t, utf8.RuneCountInString(line), 30, line)
+			words = append(words, strings.Fields(line)...)
+		}
+	}
+	assert.Equal(t, strings.Fields(text), words)
+
+	last := cues[len(cues)-1]
+	assert.Greater(t, len(strings.Fields(last[len(last)-1])), 1)
+}
+
+// === Config Tests ===
+
+func TestConfigDefaultSplitter(t *testing.T) {
+	metrics := &stream2sentence.Metrics{}
+	config := Config{Splitter: stream2sentence.SentenceSplitterConfig{Metrics: metrics}}.withDefaults()
+
+	expected := stream2sentence.CaptionConfig()
+	expected.Metrics = metrics
+	assert.Equal(t, expected, config.Splitter)
+}
+
+// === Segmenter Tests ===
+
+func TestSegmenter(t *testing.T) {
+	segmenter := NewSegmenter(Config{
+		MaxLineLength:       20,
+		MaxLines:            1,
+		CharactersPerSecond: 10,
+		MinDuration:         500 * time.Millisecond,
+		MaxDuration:         1500 * time.Millisecond,
+	})
+
+	cues := segmenter.Segment("Short one. And a somewhat longer line.", 0)
+	require.Len(t, cues, 3)
+	assert.Equal(t, Cue{Index: 0, Lines: []string{"Short one."}, Start: 0, End: time.Second}, cues[0])
+	assert.Equal(t, Cue{Index: 1, Lines: []string{"And a somewhat"}, Start: time.Second, End: 2400 * time.Millisecond}, cues[1])
+	assert.Equal(t, cues[1].End, cues[2].Start)
+	assert.Equal(t, 1200*time.Millisecond, cues[2].End-cues[2].Start)
+
+	// A late sentence starts when it arrives, an early one waits its turn
+	cues = segmenter.Segment("Hi.", 10*time.Second)
+	require.Len(t, cues, 1)
+	assert.Equal(t, 10*time.Second, cues[0].Start)
+	assert.Equal(t, 500*time.Millisecond, cues[0].End-cues[0].Start)
+
+	cues = segmenter.Segment("This is way too long to read.", 0)
+	require.Len(t, cues, 2)
+	assert.Equal(t, 10500*time.Millisecond, cues[0].Start)
+	assert.Equal(t, 4, cues[0].Index)
+}
+
+func TestGenerateCues(t *testing.T) {
+	generator := make(chan string)
+	go func() {
+		defer close(generator)
+		for _, word := range strings.Fields("This is the first sentence of the caption stream. And here comes a second one, which is a bit longer.") {
+			generator <- word + " "
+		}
+	}()
+
+	config := DefaultConfig()
+	config.MaxLineLength = 24
+
+	var cues []Cue
+	for cue := range GenerateCues(context.Background(), generator, config) {
+		cues = append(cues, cue)
+	}
+
+	require.NotEmpty(t, cues)
+	var words []string
+	for i, cue := range cues {
+		assert.Equal(t, i, cue.Index)
+		assert.Greater(t, cue.End, cue.Start)
+		if i > 0 {
+			assert.GreaterOrEqual(t, cue.Start, cues[i-1].End)
+		}
+		words = append(words, strings.Fields(cue.Text())...)
+	}
+	assert.Equal(t, "This is the first sentence of the caption stream. And here comes a second one, which is a bit longer.", strings.Join(words, " "))
+}
+
+func TestGenerateCuesMergesShortFragments(t *testing.T) {
+	generator := make(chan string)
+	go func() {
+		defer close(generator)
+		for _, word := range strings.Fields("Absolutely, I can help with that right away. Honestly, this one is easy. Yes.") {
+			generator <- word + " "
+		}
+	}()
+
+	var texts []string
+	for cue := range GenerateCues(context.Background(), generator, DefaultConfig()) {
+		texts = append(texts, cue.Text())
+	}
+
+	for _, text := range texts {
+		assert.NotContains(t, []string{"Absolutely,", "Honestly,"}, text)
+	}
+	assert.Equal(t, "Absolutely, I can help with that right away. Honestly, this one is easy. Yes.", strings.Join(strings.Fields(strings.Join(texts, " ")), " "))
+}
+
+func TestGenerateCuesCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	generator := make(chan string)
+
+	cues := GenerateCues(ctx, generator, DefaultConfig())
+	cancel()
+
+	select {
+	case _, ok := <-cues:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("cue channel not closed after cancellation")
+	}
+}
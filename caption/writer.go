@@ -0,0 +1,186 @@
+package caption
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MinCueDuration is the shortest cue written by WebVTTWriter and SRTWriter.
+// Players drop cues that do not end after they start, such as the cues of a
+// sentence spoken for no time.
+const MinCueDuration = time.Millisecond
+
+// CueWriter writes cues to a caption file as they are produced
+type CueWriter interface {
+	WriteCue(cue Cue) error
+}
+
+var (
+	_ CueWriter = (*WebVTTWriter)(nil)
+	_ CueWriter = (*SRTWriter)(nil)
+)
+
+// WebVTTWriter writes cues in the WebVTT format. Cues are written as soon
+// as they are passed to WriteCue and numbered from 1 in the order written.
+type WebVTTWriter struct {
+	w      io.Writer
+	header bool
+	count  int
+}
+
+// NewWebVTTWriter returns a WebVTTWriter writing to w
+func NewWebVTTWriter(w io.Writer) *WebVTTWriter {
+	return &WebVTTWriter{w: w}
+}
+
+// WriteHeader writes the WebVTT file header. It is called by the first
+// WriteCue and only needs to be called directly to produce a file with no
+// cues. Later calls do nothing.
+func (w *WebVTTWriter) WriteHeader() error {
+	if w.header {
+		return nil
+	}
+
+	if _, err := io.WriteString(w.w, "WEBVTT\n"); err != nil {
+		return err
+	}
+	w.header = true
+	return nil
+}
+
+// WriteCue writes a single cue. The characters &, < and > are escaped, so
+// cue text is always shown literally. Cues without text are skipped, and
+// cues ending no later than they start are extended to MinCueDuration.
+func (w *WebVTTWriter) WriteCue(cue Cue) error {
+	if err := w.WriteHeader(); err != nil {
+		return err
+	}
+
+	text := cueText(cue, escapeWebVTT)
+	if text == "" {
+		return nil
+	}
+
+	start, end := cueTimes(cue)
+	w.count++
+	_, err := fmt.Fprintf(w.w, "\n%d\n%s --> %s\n%s\n",
+		w.count, formatTimestamp(start, '.'), formatTimestamp(end, '.'), text)
+	return err
+}
+
+// SRTWriter writes cues in the SubRip (SRT) format. Cues are written as
+// soon as they are passed to WriteCue and numbered from 1 in the order
+// written.
+type SRTWriter struct {
+	w     io.Writer
+	count int
+}
+
+// NewSRTWriter returns an SRTWriter writing to w
+func NewSRTWriter(w io.Writer) *SRTWriter {
+	return &SRTWriter{w: w}
+}
+
+// WriteCue writes a single cue. SRT has no escape mechanism, so the text is
+// written as is apart from blank lines, which would end the cue early. Cues
+// without text are skipped, and cues ending no later than they start are
+// extended to MinCueDuration.
+func (w *SRTWriter) WriteCue(cue Cue) error {
+	text := cueText(cue, nil)
+	if text == "" {
+		return nil
+	}
+
+	separator := ""
+	if w.count > 0 {
+		separator = "\n"
+	}
+
+	start, end := cueTimes(cue)
+	w.count++
+	_, err := fmt.Fprintf(w.w, "%s%d\n%s --> %s\n%s\n",
+		separator, w.count, formatTimestamp(start, ','), formatTimestamp(end, ','), text)
+	return err
+}
+
+// cueTimes returns the start and end of a cue at the precision of the
+// timestamps, with the end at least MinCueDuration after the start
+func cueTimes(cue Cue) (start, end time.Duration) {
+	start = max(cue.Start, 0).Round(time.Millisecond)
+	end = max(cue.End.Round(time.Millisecond), start+MinCueDuration)
+	return start, end
+}
+
+// formatTimestamp formats d as hh:mm:ss followed by the decimal separator
+// and milliseconds. Negative durations are clamped to zero.
+func formatTimestamp(d time.Duration, separator byte) string {
+	d = max(d, 0).Round(time.Millisecond)
+
+	hours := d / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute / time.Second
+	milliseconds := d % time.Second / time.Millisecond
+
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", hours, minutes, seconds, separator, milliseconds)
+}
+
+// webVTTEscaper escapes the characters with a special meaning in WebVTT cue text
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeWebVTT escapes a line of WebVTT cue text
+func escapeWebVTT(line string) string {
+	return webVTTEscaper.Replace(line)
+}
+
+// cueText returns the non-blank lines of the cue, escaped by escape if set
+func cueText(cue Cue, escape func(string) string) string {
+	var lines []string
+	for _, line := range cue.Lines {
+		for _, part := range strings.FieldsFunc(line, isLineBreak) {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			if escape != nil {
+				part = escape(part)
+			}
+			lines = append(lines, part)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isLineBreak reports whether r ends a line of cue text
+func isLineBreak(r rune) bool {
+	return r == '\n' || r == '\r'
+}
+
+// WriteSpoken lays out the sentences as they are spoken, one after another,
+// and writes each cue to w as soon as its sentence arrives. duration returns
+// how long a sentence is spoken, e.g. the length of its synthesized audio;
+// if it is nil, durations are estimated at DefaultWordsPerMinute. It returns
+// once sentences is closed or writing fails; in the latter case the caller
+// should stop the producer, e.g. by cancelling its context.
+func WriteSpoken(w CueWriter, sentences <-chan string, config Config, duration func(sentence string) time.Duration) error {
+	if duration == nil {
+		duration = func(sentence string) time.Duration {
+			return EstimateDuration(sentence, DefaultWordsPerMinute)
+		}
+	}
+
+	segmenter := NewSegmenter(config)
+	for sentence := range sentences {
+		for _, cue := range segmenter.SegmentSpoken(sentence, duration(sentence)) {
+			if err := w.WriteCue(cue); err != nil {
+				return err
+			}
+		}
+	}
+
+	if vtt, ok := w.(*WebVTTWriter); ok {
+		return vtt.WriteHeader()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package caption
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// failingWriter is a CueWriter that always fails
+type failingWriter struct{}
+
+func (failingWriter) WriteCue(Cue) error {
+	return errors.New("disk full")
+}
+
+// === Writer Tests ===
+
+func TestFormatTimestamp(t *testing.T) {
+	assert.Equal(t, "00:00:00.000", formatTimestamp(0, '.'))
+	assert.Equal(t, "00:00:01,500", formatTimestamp(1500*time.Millisecond, ','))
+	assert.Equal(t, "01:02:03.004", formatTimestamp(time.Hour+2*time.Minute+3*time.Second+4*time.Millisecond, '.'))
+	assert.Equal(t, "00:00:00.001", formatTimestamp(600*time.Microsecond, '.'))
+	assert.Equal(t, "00:00:00.000", formatTimestamp(-time.Second, '.'))
+}
+
+func TestWebVTTWriter(t *testing.T) {
+	var out strings.Builder
+	writer := NewWebVTTWriter(&out)
+
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{"Hello there."}, Start: 0, End: 1500 * time.Millisecond}))
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{"  ", "\n"}, Start: 2 * time.Second, End: 3 * time.Second}))
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{"Fish & chips <3", "a --> b"}, Start: 1500 * time.Millisecond, End: 4 * time.Second}))
+
+	assert.Equal(t, `WEBVTT
+
+1
+00:00:00.000 --> 00:00:01.500
+Hello there.
+
+2
+00:00:01.500 --> 00:00:04.000
+Fish &amp; chips &lt;3
+a --&gt; b
+`, out.String())
+}
+
+func TestWebVTTWriterEmpty(t *testing.T) {
+	var out strings.Builder
+	writer := NewWebVTTWriter(&out)
+
+	require.NoError(t, writer.WriteHeader())
+	require.NoError(t, writer.WriteHeader())
+	assert.Equal(t, "WEBVTT\n", out.String())
+}
+
+func TestSRTWriter(t *testing.T) {
+	var out strings.Builder
+	writer := NewSRTWriter(&out)
+
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{""}, Start: 0, End: time.Second}))
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{"First line", "second line."}, Start: 0, End: 2 * time.Second}))
+	require.NoError(t, writer.WriteCue(Cue{Lines: []string{"Blank\n\nlines <i>kept</i> out."}, Start: 2 * time.Second, End: 61 * time.Second}))
+
+	assert.Equal(t, `1
+00:00:00,000 --> 00:00:02,000
+First line
+second line.
+
+2
+00:00:02,000 --> 00:01:01,000
+Blank
+lines <i>kept</i> out.
+`, out.String())
+}
+
+func TestWriterMinCueDuration(t *testing.T) {
+	// A sentence spoken for no time, and one ending within a millisecond
+	cues := []Cue{
+		{Lines: []string{"Instant."}, Start: time.Second, End: time.Second},
+		{Lines: []string{"Blink."}, Start: 2 * time.Second, End: 2*time.Second + 200*time.Microsecond},
+	}
+
+	var vtt, srt strings.Builder
+	vttWriter, srtWriter := NewWebVTTWriter(&vtt), NewSRTWriter(&srt)
+	for _, cue := range cues {
+		require.NoError(t, vttWriter.WriteCue(cue))
+		require.NoError(t, srtWriter.WriteCue(cue))
+	}
+
+	assert.Contains(t, vtt.String(), "00:00:01.000 --> 00:00:01.001\nInstant.")
+	assert.Contains(t, vtt.String(), "00:00:02.000 --> 00:00:02.001\nBlink.")
+	assert.Contains(t, srt.String(), "00:00:01,000 --> 00:00:01,001\nInstant.")
+	assert.Contains(t, srt.String(), "00:00:02,000 --> 00:00:02,001\nBlink.")
+}
+
+// === Spoken Timing Tests ===
+
+func TestEstimateDuration(t *testing.T) {
+	assert.Equal(t, 2*time.Second, EstimateDuration("one two three four five", 150))
+	assert.Equal(t, 800*time.Millisecond, EstimateDuration("one two", 0))
+	assert.Equal(t, time.Duration(0), EstimateDuration("   ", 150))
+}
+
+func TestSegmentSpoken(t *testing.T) {
+	segmenter := NewSegmenter(Config{MaxLineLength: 10, MaxLines: 1})
+
+	cues := segmenter.SegmentSpoken("Abcd efgh ijkl mnop.", 4*time.Second)
+	require.Len(t, cues, 2)
+	assert.Equal(t, Cue{Index: 0, Lines: []string{"Abcd efgh"}, Start: 0, End: 1894736842}, cues[0])
+	assert.Equal(t, Cue{Index: 1, Lines: []string{"ijkl mnop."}, Start: 1894736842, End: 4 * time.Second}, cues[1])
+
+	// Silence advances the timeline
+	assert.Empty(t, segmenter.SegmentSpoken("", time.Second))
+
+	cues = segmenter.SegmentSpoken("Next.", time.Second)
+	require.Len(t, cues, 1)
+	assert.Equal(t, Cue{Index: 2, Lines: []string{"Next."}, Start: 5 * time.Second, End: 6 * time.Second}, cues[0])
+}
+
+func TestWriteSpoken(t *testing.T) {
+	sentences := stream2sentence.GenerateSentencesFromString(
+		"This is the first sentence. And a second one follows it.",
+		stream2sentence.GenerateSentencesConfig{SentenceSplitterConfig: stream2sentence.DefaultConfig()},
+	)
+
+	durations := map[string]time.Duration{
+		"This is the first sentence.":  1200 * time.Millisecond,
+		"And a second one follows it.": 1800 * time.Millisecond,
+	}
+
+	var out strings.Builder
+	err := WriteSpoken(NewSRTWriter(&out), sentences, DefaultConfig(), func(sentence string) time.Duration {
+		return durations[sentence]
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `1
+00:00:00,000 --> 00:00:01,200
+This is the first sentence.
+
+2
+00:00:01,200 --> 00:00:03,000
+And a second one follows it.
+`, out.String())
+}
+
+func TestWriteSpokenEstimated(t *testing.T) {
+	sentences := make(chan string, 2)
+	sentences <- "Five words are spoken here."
+	sentences <- "And three more."
+	close(sentences)
+
+	var out strings.Builder
+	require.NoError(t, WriteSpoken(NewWebVTTWriter(&out), sentences, DefaultConfig(), nil))
+	assert.Contains(t, out.String(), "00:00:00.000 --> 00:00:02.000\nFive words are spoken here.")
+	assert.Contains(t, out.String(), "00:00:02.000 --> 00:00:03.200\nAnd three more.")
+
+	// Without sentences a WebVTT file still gets its header
+	empty := make(chan string)
+	close(empty)
+
+	out.Reset()
+	require.NoError(t, WriteSpoken(NewWebVTTWriter(&out), empty, DefaultConfig(), nil))
+	assert.Equal(t, "WEBVTT\n", out.String())
+}
+
+func TestWriteSpokenError(t *testing.T) {
+	sentences := make(chan string, 1)
+	sentences <- "Never written."
+	close(sentences)
+
+	assert.EqualError(t, WriteSpoken(failingWriter{}, sentences, DefaultConfig(), nil), "disk full")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/eval"
+)
+
+// runEval implements the eval command
+func runEval(args []string) error {
+	overrides := stream2sentence.DefaultConfig()
+
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: stream2sentence eval [flags] corpus.jsonl...")
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", "", "JSON or YAML configuration `file`; STREAM2SENTENCE_* environment variables override it")
+	flags.IntVar(&overrides.ContextSize, "context-size", overrides.ContextSize, "context window size")
+	flags.IntVar(&overrides.MinimumSentenceLength, "min-sentence-length", overrides.MinimumSentenceLength, "minimum sentence length")
+	flags.IntVar(&overrides.MinimumFirstFragmentLength, "min-first-fragment-length", overrides.MinimumFirstFragmentLength, "minimum first fragment length")
+	flags.StringVar(&overrides.SentenceFragmentDelimiters, "fragment-delimiters", overrides.SentenceFragmentDelimiters, "sentence fragment delimiters")
+	flags.StringVar(&overrides.FullSentenceDelimiters, "full-delimiters", overrides.FullSentenceDelimiters, "full sentence delimiters")
+	flags.TextVar(&overrides.QuickYieldMode, "quick-yield", overrides.QuickYieldMode, "quick yield mode: none, first or all")
+	flags.TextVar(&overrides.CleanupOptions, "cleanup", overrides.CleanupOptions, "comma-separated cleanup flags: links, emojis, table, strip, all or none")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := stream2sentence.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	// Flags given on the command line take precedence over the configuration
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "context-size":
+			config.ContextSize = overrides.ContextSize
+		case "min-sentence-length":
+			config.MinimumSentenceLength = overrides.MinimumSentenceLength
+		case "min-first-fragment-length":
+			config.MinimumFirstFragmentLength = overrides.MinimumFirstFragmentLength
+		case "fragment-delimiters":
+			config.SentenceFragmentDelimiters = overrides.SentenceFragmentDelimiters
+		case "full-delimiters":
+			config.FullSentenceDelimiters = overrides.FullSentenceDelimiters
+		case "quick-yield":
+			config.QuickYieldMode = overrides.QuickYieldMode
+		case "cleanup":
+			config.CleanupOptions = overrides.CleanupOptions
+		}
+	})
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		flags.Usage()
+		return fmt.Errorf("no corpus given")
+	}
+
+	var all []eval.Record
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "corpus\tdocs\tprecision\trecall\tF1\tfirst fragment (chars)\t")
+
+	for _, path := range paths {
+		records, err := eval.LoadCorpusFile(path)
+		if err != nil {
+			return err
+		}
+		all = append(all, records...)
+		printResult(table, filepath.Base(path), eval.Evaluate(records, config))
+	}
+
+	if len(paths) > 1 {
+		printResult(table, "total", eval.Evaluate(all, config))
+	}
+
+	return table.Flush()
+}
+
+// printResult writes one result row
+func printResult(table *tabwriter.Writer, name string, result eval.Result) {
+	fmt.Fprintf(table, "%s\t%d\t%.3f\t%.3f\t%.3f\t%.1f\t\n",
+		name, result.Documents, result.Precision, result.Recall, result.F1, result.FirstFragmentLatency)
+}
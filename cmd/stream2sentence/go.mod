@@ -0,0 +1,17 @@
+module github.com/txt-dot/stream2sentence/cmd/stream2sentence
+
+go 1.24.0
+
+require (
+	github.com/txt-dot/stream2sentence v0.1.0
+	github.com/txt-dot/stream2sentence/server v0.1.0
+)
+
+require (
+	github.com/coder/websocket v1.8.15 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/txt-dot/stream2sentence => ../../
+
+replace github.com/txt-dot/stream2sentence/server => ../../server
@@ -0,0 +1,41 @@
+// Command stream2sentence provides command line tooling around the
+// stream2sentence package
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: stream2sentence <command> [flags]
+
+commands:
+  eval    score sentence boundary accuracy against gold corpora
+  serve   split text streamed over HTTP and WebSocket connections
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "eval":
+		err = runEval(os.Args[2:])
+	case "serve":
+		err = runServe(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "stream2sentence:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/server"
+)
+
+// runServe implements the serve command
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: stream2sentence serve [flags]")
+		flags.PrintDefaults()
+	}
+	addr := flags.String("addr", ":8080", "TCP `address` to listen on")
+	configPath := flags.String("config", "", "JSON or YAML configuration `file`; STREAM2SENTENCE_* environment variables override it")
+	origins := flags.String("origins", "", "comma-separated host `patterns` allowed to open WebSocket connections from browsers")
+	flush := flags.Bool("flush-partial", false, "send the buffered text when a client's input breaks off")
+	maxInput := flags.Int64("max-input-bytes", server.DefaultMaxInputBytes, "maximum `bytes` of input per session, or -1 for no limit")
+	maxBuffered := flags.Int("max-buffered-bytes", server.DefaultMaxBufferedBytes, "`bytes` of text received without a sentence after which it is flushed, or -1 for no limit")
+	shutdownTimeout := flags.Duration("shutdown-timeout", 10*time.Second, "how long running sessions may take to finish on shutdown")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	splitter, err := stream2sentence.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	config := server.Config{
+		Splitter:         splitter,
+		MaxInputBytes:    *maxInput,
+		MaxBufferedBytes: *maxBuffered,
+	}
+	if *origins != "" {
+		config.OriginPatterns = strings.Split(*origins, ",")
+	}
+	if *flush {
+		config.UpstreamErrorPolicy = stream2sentence.FlushPartial
+	}
+	s := server.New(config)
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+	log.Printf("listening on %s", l.Addr())
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal kills the process right away
+	stop()
+
+	log.Printf("shutting down, waiting up to %s for running sessions", *shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
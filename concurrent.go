@@ -0,0 +1,119 @@
+package stream2sentence
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrInputClosed is returned by ConcurrentSplitter.Add after CloseInput
+var ErrInputClosed = errors.New("stream2sentence: input closed")
+
+// ConcurrentSplitter is a thread-safe splitter for producers and a consumer
+// running on different goroutines. Any number of goroutines may call Add
+// while a single consumer ranges over Sentences, which waits for new input
+// until CloseInput is called. Chunks from different producers are processed
+// in the order Add was called, so producers should add whole sentences if
+// their text must not interleave.
+type ConcurrentSplitter struct {
+	splitter *SentenceSplitter
+
+	mu     sync.Mutex
+	closed bool
+
+	// ready holds a pending wake-up for the consumer
+	ready chan struct{}
+}
+
+// NewConcurrentSplitter creates a ConcurrentSplitter with the given configuration
+func NewConcurrentSplitter(config SentenceSplitterConfig) *ConcurrentSplitter {
+	return &ConcurrentSplitter{
+		splitter: NewSentenceSplitter(config),
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// Add queues a text chunk. It is safe for concurrent use and returns
+// ErrInputClosed once CloseInput has been called.
+func (c *ConcurrentSplitter) Add(chunk string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrInputClosed
+	}
+
+	c.splitter.Add(chunk)
+	c.wake()
+	return nil
+}
+
+// CloseInput marks the end of the input. Sentences flushes the remaining
+// text once all queued chunks are processed.
+func (c *ConcurrentSplitter) CloseInput() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.wake()
+	}
+}
+
+// wake signals the consumer without blocking
+func (c *ConcurrentSplitter) wake() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Sentences returns an iterator over the sentences of all added chunks. It
+// must have a single consumer and ends after CloseInput once everything is
+// flushed, or with an error when ctx is done or a callback fails.
+func (c *ConcurrentSplitter) Sentences(ctx context.Context) iter.Seq2[Sentence, error] {
+	return func(yield func(Sentence, error) bool) {
+		var pending []Sentence
+		collect := func(sentence Sentence) error {
+			pending = append(pending, sentence)
+			return nil
+		}
+
+		for {
+			// Read the flag before processing, so every chunk added before
+			// the input was closed is processed in this round
+			c.mu.Lock()
+			closed := c.closed
+			c.mu.Unlock()
+
+			err := c.splitter.process(collect)
+			if err == nil && closed {
+				err = c.splitter.flush(collect)
+			}
+
+			for _, sentence := range pending {
+				if !yield(sentence, nil) {
+					return
+				}
+			}
+			pending = pending[:0]
+
+			if err != nil {
+				yield(Sentence{}, err)
+				return
+			}
+
+			if closed {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				yield(Sentence{}, ctx.Err())
+				return
+			case <-c.ready:
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package stream2sentence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	numProducers         = 8
+	sentencesPerProducer = 50
+)
+
+// produce runs producers adding whole sentences concurrently and returns
+// the sentences they added
+func produce(add func(string)) []string {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		expected []string
+	)
+
+	for p := range numProducers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range sentencesPerProducer {
+				sentence := fmt.Sprintf("Producer %d says hello number %d.", p, i)
+				add(sentence + " ")
+
+				mu.Lock()
+				expected = append(expected, sentence)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return expected
+}
+
+// assertAllSentences checks that every expected sentence was emitted once
+func assertAllSentences(t *testing.T, expected, emitted []string) {
+	t.Helper()
+
+	joined := " " + strings.Join(emitted, " ") + " "
+	for _, sentence := range expected {
+		assert.Equal(t, 1, strings.Count(joined, " "+sentence+" "), sentence)
+	}
+}
+
+// === Concurrency Tests ===
+
+func TestSentenceSplitterConcurrentAdd(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+
+	done := make(chan []string)
+	go func() {
+		done <- produce(splitter.Add)
+	}()
+
+	// A single consumer drains while the producers are adding
+	var (
+		emitted  []string
+		expected []string
+	)
+	for expected == nil {
+		select {
+		case expected = <-done:
+		default:
+		}
+
+		for sentence := range splitter.Stream() {
+			emitted = append(emitted, sentence)
+		}
+	}
+	for sentence := range splitter.Flush() {
+		emitted = append(emitted, sentence)
+	}
+
+	assertAllSentences(t, expected, emitted)
+}
+
+func TestConcurrentSplitter(t *testing.T) {
+	splitter := NewConcurrentSplitter(DefaultConfig())
+
+	done := make(chan []string)
+	go func() {
+		expected := produce(func(chunk string) {
+			assert.NoError(t, splitter.Add(chunk))
+		})
+		splitter.CloseInput()
+		done <- expected
+	}()
+
+	var emitted []string
+	for sentence, err := range splitter.Sentences(context.Background()) {
+		require.NoError(t, err)
+		emitted = append(emitted, sentence.Text)
+	}
+
+	assertAllSentences(t, <-done, emitted)
+	assert.ErrorIs(t, splitter.Add("Too late."), ErrInputClosed)
+}
+
+func TestConcurrentSplitterCancellation(t *testing.T) {
+	splitter := NewConcurrentSplitter(DefaultConfig())
+	require.NoError(t, splitter.Add("This is the first sentence. This one is still open"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		emitted []string
+		err     error
+	)
+	for sentence, sentenceErr := range splitter.Sentences(ctx) {
+		if sentenceErr != nil {
+			err = sentenceErr
+			break
+		}
+		emitted = append(emitted, sentence.Text)
+		cancel()
+	}
+
+	assert.Equal(t, []string{"This is the first sentence."}, emitted)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,178 @@
+package stream2sentence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConfig matches every ConfigError via errors.Is
+var ErrInvalidConfig = errors.New("stream2sentence: invalid config")
+
+// ConfigError reports an invalid field of a SentenceSplitterConfig
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("stream2sentence: invalid %s: %s", e.Field, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidConfig
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// Validate checks the tuning options and returns a ConfigError for every
+// invalid field, joined with errors.Join
+func (c SentenceSplitterConfig) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...any) {
+		errs = append(errs, &ConfigError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if c.ContextSize < 0 {
+		invalid("ContextSize", "must not be negative, got %d", c.ContextSize)
+	}
+
+	if c.MinimumSentenceLength < 0 {
+		invalid("MinimumSentenceLength", "must not be negative, got %d", c.MinimumSentenceLength)
+	}
+
+	if c.MinimumFirstFragmentLength < 0 {
+		invalid("MinimumFirstFragmentLength", "must not be negative, got %d", c.MinimumFirstFragmentLength)
+	}
+
+	if c.QuickYieldMode < NoQuickYield || c.QuickYieldMode > QuickYieldAllFragments {
+		invalid("QuickYieldMode", "unknown mode %d", c.QuickYieldMode)
+	}
+
+	if unknown := c.CleanupOptions &^ CleanupAll; unknown != 0 {
+		invalid("CleanupOptions", "unknown flags %#x", int(unknown))
+	}
+
+	if c.SentenceFragmentDelimiters == "" {
+		invalid("SentenceFragmentDelimiters", "must not be empty")
+	}
+
+	if c.FullSentenceDelimiters == "" {
+		invalid("FullSentenceDelimiters", "must not be empty")
+	}
+
+	// Text is only ever split at fragment delimiters, so a full sentence
+	// delimiter missing from them would never end a sentence
+	var missing []string
+	for _, r := range c.FullSentenceDelimiters {
+		if !strings.ContainsRune(c.SentenceFragmentDelimiters, r) {
+			missing = append(missing, fmt.Sprintf("%q", r))
+		}
+	}
+	if len(missing) > 0 {
+		invalid("FullSentenceDelimiters", "%s not in SentenceFragmentDelimiters", strings.Join(missing, ", "))
+	}
+
+	if c.Output.Buffer < 0 {
+		invalid("Output.Buffer", "must not be negative, got %d", c.Output.Buffer)
+	}
+
+	if c.Output.LagPolicy < LagBlock || c.Output.LagPolicy > LagCoalesce {
+		invalid("Output.LagPolicy", "unknown policy %d", c.Output.LagPolicy)
+	}
+
+	if c.Output.CoalesceLength < 0 {
+		invalid("Output.CoalesceLength", "must not be negative, got %d", c.Output.CoalesceLength)
+	}
+
+	if c.Adaptive.HighWater < 0 {
+		invalid("Adaptive.HighWater", "must not be negative, got %d", c.Adaptive.HighWater)
+	}
+
+	if c.Adaptive.LaggingMinimumSentenceLength < 0 {
+		invalid("Adaptive.LaggingMinimumSentenceLength", "must not be negative, got %d", c.Adaptive.LaggingMinimumSentenceLength)
+	} else if c.Adaptive.HighWater > 0 && c.Adaptive.LaggingMinimumSentenceLength < c.MinimumSentenceLength {
+		invalid("Adaptive.LaggingMinimumSentenceLength", "must not be below MinimumSentenceLength %d, got %d",
+			c.MinimumSentenceLength, c.Adaptive.LaggingMinimumSentenceLength)
+	}
+
+	if mode := c.Adaptive.LaggingQuickYieldMode; mode != nil && (*mode < NoQuickYield || *mode > QuickYieldAllFragments) {
+		invalid("Adaptive.LaggingQuickYieldMode", "unknown mode %d", *mode)
+	}
+
+	if depth := c.Adaptive.LaggingQuickYieldDepth; depth < 0 || depth > max(c.Adaptive.HighWater, 0) {
+		invalid("Adaptive.LaggingQuickYieldDepth", "must be between 0 and HighWater %d, got %d", c.Adaptive.HighWater, depth)
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithDefaults returns a copy of the configuration with unset fields filled
+// in from DefaultConfig. Empty delimiters and zero lengths count as unset,
+// while a zero QuickYieldMode or CleanupOptions is a valid choice and kept,
+// unless all tuning options are zero.
+func (c SentenceSplitterConfig) WithDefaults() SentenceSplitterConfig {
+	return c.WithDefaultsFrom(DefaultConfig())
+}
+
+// WithDefaultsFrom is like WithDefaults, but fills in unset fields from
+// defaults, e.g. a preset
+func (c SentenceSplitterConfig) WithDefaultsFrom(defaults SentenceSplitterConfig) SentenceSplitterConfig {
+	if c.tuningZero() {
+		return c.WithTuning(defaults)
+	}
+
+	if c.ContextSize == 0 {
+		c.ContextSize = defaults.ContextSize
+	}
+	if c.MinimumSentenceLength == 0 {
+		c.MinimumSentenceLength = defaults.MinimumSentenceLength
+	}
+	if c.MinimumFirstFragmentLength == 0 {
+		c.MinimumFirstFragmentLength = defaults.MinimumFirstFragmentLength
+	}
+	if c.SentenceFragmentDelimiters == "" {
+		c.SentenceFragmentDelimiters = defaults.SentenceFragmentDelimiters
+	}
+	if c.FullSentenceDelimiters == "" {
+		c.FullSentenceDelimiters = defaults.FullSentenceDelimiters
+	}
+
+	return c
+}
+
+// WithTuning returns a copy of the configuration with its tuning options,
+// i.e. the lengths, quick yield mode, cleanup flags and delimiters, taken
+// from tuning, e.g. a preset. The runtime options of the configuration,
+// such as Metrics, Observer, Callbacks, Output and Adaptive, are kept.
+func (c SentenceSplitterConfig) WithTuning(tuning SentenceSplitterConfig) SentenceSplitterConfig {
+	c.ContextSize = tuning.ContextSize
+	c.MinimumSentenceLength = tuning.MinimumSentenceLength
+	c.MinimumFirstFragmentLength = tuning.MinimumFirstFragmentLength
+	c.QuickYieldMode = tuning.QuickYieldMode
+	c.CleanupOptions = tuning.CleanupOptions
+	c.SentenceFragmentDelimiters = tuning.SentenceFragmentDelimiters
+	c.FullSentenceDelimiters = tuning.FullSentenceDelimiters
+	return c
+}
+
+// tuningZero reports whether all tuning options are unset
+func (c SentenceSplitterConfig) tuningZero() bool {
+	return c.ContextSize == 0 &&
+		c.MinimumSentenceLength == 0 &&
+		c.MinimumFirstFragmentLength == 0 &&
+		c.QuickYieldMode == NoQuickYield &&
+		c.CleanupOptions == 0 &&
+		c.SentenceFragmentDelimiters == "" &&
+		c.FullSentenceDelimiters == ""
+}
+
+// NewSentenceSplitterE fills in defaults for unset fields, validates the
+// configuration and creates a SentenceSplitter from it
+func NewSentenceSplitterE(config SentenceSplitterConfig) (*SentenceSplitter, error) {
+	config = config.WithDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return NewSentenceSplitter(config), nil
+}
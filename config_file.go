@@ -0,0 +1,194 @@
+package stream2sentence
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables read by ApplyEnv and
+// LoadConfig. Each tuning option is read from EnvPrefix followed by the
+// upper-cased name of its JSON field, e.g. STREAM2SENTENCE_CONTEXT_SIZE.
+// STREAM2SENTENCE_PRESET selects the preset a configuration file builds on.
+const EnvPrefix = "STREAM2SENTENCE_"
+
+// Configuration file formats accepted by ParseConfig
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// configFile is the layout of a configuration file: an optional preset
+// name plus any tuning options that override it
+type configFile struct {
+	Preset                 string `json:"preset" yaml:"preset"`
+	SentenceSplitterConfig `yaml:",inline"`
+}
+
+// ParseConfig decodes a JSON or YAML configuration. Options missing from
+// the data keep the values of the preset named by its "preset" field, or of
+// DefaultConfig if there is none. QuickYieldMode and CleanupOptions are
+// given by name, e.g. "first-fragment" and "links,emojis". Unknown fields
+// are an error. The result is not validated.
+func ParseConfig(data []byte, format string) (SentenceSplitterConfig, error) {
+	return parseConfig(data, format, "")
+}
+
+// parseConfig decodes a configuration on top of the named preset, falling
+// back to the preset named in the data if preset is empty
+func parseConfig(data []byte, format, preset string) (SentenceSplitterConfig, error) {
+	var file configFile
+	if err := decodeConfig(data, format, &file, false); err != nil {
+		return SentenceSplitterConfig{}, err
+	}
+
+	if preset == "" {
+		preset = file.Preset
+	}
+	if preset == "" {
+		preset = "default"
+	}
+
+	base, err := Preset(preset)
+	if err != nil {
+		return SentenceSplitterConfig{}, err
+	}
+
+	file = configFile{SentenceSplitterConfig: base}
+	if err := decodeConfig(data, format, &file, true); err != nil {
+		return SentenceSplitterConfig{}, err
+	}
+
+	return file.SentenceSplitterConfig, nil
+}
+
+// decodeConfig decodes data in the given format into file
+func decodeConfig(data []byte, format string, file *configFile, strict bool) error {
+	var err error
+
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			decoder.DisallowUnknownFields()
+		}
+		err = decoder.Decode(file)
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(strict)
+		err = decoder.Decode(file)
+		if errors.Is(err, io.EOF) {
+			// An empty document leaves everything at its defaults
+			err = nil
+		}
+	default:
+		return fmt.Errorf("stream2sentence: unknown config format %q", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("stream2sentence: invalid %s config: %w", format, err)
+	}
+	return nil
+}
+
+// LoadConfig reads a configuration file, applies environment variable
+// overrides and validates the result. The format is taken from the file
+// extension: .json, .yaml or .yml. If path is empty, the configuration is
+// built from the environment alone.
+func LoadConfig(path string) (SentenceSplitterConfig, error) {
+	preset := os.Getenv(EnvPrefix + "PRESET")
+
+	var config SentenceSplitterConfig
+	if path == "" {
+		var err error
+		if config, err = Preset(cmp.Or(preset, "default")); err != nil {
+			return SentenceSplitterConfig{}, err
+		}
+	} else {
+		var format string
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".json":
+			format = FormatJSON
+		case ".yaml", ".yml":
+			format = FormatYAML
+		default:
+			return SentenceSplitterConfig{}, fmt.Errorf("stream2sentence: unknown config file extension %q", ext)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return SentenceSplitterConfig{}, err
+		}
+
+		if config, err = parseConfig(data, format, preset); err != nil {
+			return SentenceSplitterConfig{}, fmt.Errorf("%w in %s", err, path)
+		}
+	}
+
+	config, err := ApplyEnv(config)
+	if err != nil {
+		return SentenceSplitterConfig{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return SentenceSplitterConfig{}, err
+	}
+	return config, nil
+}
+
+// ApplyEnv returns a copy of the configuration with every tuning option that
+// is set in the environment overridden. See EnvPrefix for the variable names.
+func ApplyEnv(config SentenceSplitterConfig) (SentenceSplitterConfig, error) {
+	ints := []struct {
+		name  string
+		field *int
+	}{
+		{"CONTEXT_SIZE", &config.ContextSize},
+		{"MINIMUM_SENTENCE_LENGTH", &config.MinimumSentenceLength},
+		{"MINIMUM_FIRST_FRAGMENT_LENGTH", &config.MinimumFirstFragmentLength},
+	}
+	for _, option := range ints {
+		if value, ok := os.LookupEnv(EnvPrefix + option.name); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return SentenceSplitterConfig{}, fmt.Errorf("stream2sentence: invalid %s%s: %w", EnvPrefix, option.name, err)
+			}
+			*option.field = n
+		}
+	}
+
+	texts := []struct {
+		name  string
+		field encoding.TextUnmarshaler
+	}{
+		{"QUICK_YIELD_MODE", &config.QuickYieldMode},
+		{"CLEANUP_OPTIONS", &config.CleanupOptions},
+	}
+	for _, option := range texts {
+		if value, ok := os.LookupEnv(EnvPrefix + option.name); ok {
+			if err := option.field.UnmarshalText([]byte(value)); err != nil {
+				return SentenceSplitterConfig{}, fmt.Errorf("stream2sentence: invalid %s%s: %w", EnvPrefix, option.name, err)
+			}
+		}
+	}
+
+	// Delimiters are taken verbatim, so whitespace is significant
+	if value, ok := os.LookupEnv(EnvPrefix + "SENTENCE_FRAGMENT_DELIMITERS"); ok {
+		config.SentenceFragmentDelimiters = value
+	}
+	if value, ok := os.LookupEnv(EnvPrefix + "FULL_SENTENCE_DELIMITERS"); ok {
+		config.FullSentenceDelimiters = value
+	}
+
+	return config, nil
+}
@@ -0,0 +1,273 @@
+package stream2sentence
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// === Text Marshaling Tests ===
+
+func TestQuickYieldModeText(t *testing.T) {
+	for _, mode := range []QuickYieldMode{NoQuickYield, QuickYieldFirstFragment, QuickYieldAllFragments} {
+		text, err := mode.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, mode.String(), string(text))
+
+		var decoded QuickYieldMode
+		require.NoError(t, decoded.UnmarshalText(text))
+		assert.Equal(t, mode, decoded)
+	}
+
+	var mode QuickYieldMode
+	require.NoError(t, mode.UnmarshalText([]byte("first")))
+	assert.Equal(t, QuickYieldFirstFragment, mode)
+
+	assert.Error(t, mode.UnmarshalText([]byte("sometimes")))
+	_, err := QuickYieldMode(7).MarshalText()
+	assert.Error(t, err)
+	assert.Equal(t, "QuickYieldMode(7)", QuickYieldMode(7).String())
+}
+
+func TestCleanupFlagsText(t *testing.T) {
+	tests := []struct {
+		flags CleanupFlags
+		text  string
+	}{
+		{0, "none"},
+		{CleanupLinks, "links"},
+		{CleanupBasic, "links,emojis,strip"},
+		{CleanupAll, "links,emojis,table,strip"},
+	}
+
+	for _, tt := range tests {
+		text, err := tt.flags.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, tt.text, string(text))
+
+		var decoded CleanupFlags
+		require.NoError(t, decoded.UnmarshalText(text))
+		assert.Equal(t, tt.flags, decoded)
+	}
+
+	var flags CleanupFlags
+	require.NoError(t, flags.UnmarshalText([]byte("basic, table")))
+	assert.Equal(t, CleanupAll, flags)
+
+	assert.Error(t, flags.UnmarshalText([]byte("links,markdown")))
+	_, err := (CleanupLinks | 1<<10).MarshalText()
+	assert.Error(t, err)
+}
+
+func TestConfigJSONRoundTrip(t *testing.T) {
+	config := DefaultConfig()
+	config.QuickYieldMode = QuickYieldFirstFragment
+	config.CleanupOptions = CleanupLinks | StripText
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"quick_yield_mode":"first-fragment"`)
+	assert.Contains(t, string(data), `"cleanup_options":"links,strip"`)
+
+	var decoded SentenceSplitterConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+func TestConfigJSONNumericOptions(t *testing.T) {
+	// Configurations written before the options had names hold numbers
+	var config SentenceSplitterConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"quick_yield_mode":1,"cleanup_options":9}`), &config))
+	assert.Equal(t, QuickYieldFirstFragment, config.QuickYieldMode)
+	assert.Equal(t, CleanupLinks|StripText, config.CleanupOptions)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"quick_yield_mode":"2","cleanup_options":null}`), &config))
+	assert.Equal(t, QuickYieldAllFragments, config.QuickYieldMode)
+	assert.Equal(t, CleanupLinks|StripText, config.CleanupOptions)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"quick_yield_mode":3}`), &config))
+	assert.Error(t, json.Unmarshal([]byte(`{"cleanup_options":1024}`), &config))
+	assert.Error(t, json.Unmarshal([]byte(`{"quick_yield_mode":true}`), &config))
+
+	parsed, err := ParseConfig([]byte("quick_yield_mode: 0\ncleanup_options: 1\n"), FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, NoQuickYield, parsed.QuickYieldMode)
+	assert.Equal(t, CleanupLinks, parsed.CleanupOptions)
+}
+
+// === Config File Tests ===
+
+func TestParseConfig(t *testing.T) {
+	lowLatency, err := Preset("low-latency")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   func(*SentenceSplitterConfig)
+		base   SentenceSplitterConfig
+	}{
+		{
+			name:   "Empty JSON",
+			format: FormatJSON,
+			data:   `{}`,
+			base:   DefaultConfig(),
+		},
+		{
+			name:   "Empty YAML",
+			format: FormatYAML,
+			data:   ``,
+			base:   DefaultConfig(),
+		},
+		{
+			name:   "JSON overrides",
+			format: FormatJSON,
+			data:   `{"context_size": 20, "quick_yield_mode": "none", "cleanup_options": "strip"}`,
+			base:   DefaultConfig(),
+			want: func(c *SentenceSplitterConfig) {
+				c.ContextSize = 20
+				c.QuickYieldMode = NoQuickYield
+				c.CleanupOptions = StripText
+			},
+		},
+		{
+			name:   "YAML preset with overrides",
+			format: FormatYAML,
+			data:   "preset: low-latency\nminimum_sentence_length: 4\ncleanup_options: links,emojis\n",
+			base:   lowLatency,
+			want: func(c *SentenceSplitterConfig) {
+				c.MinimumSentenceLength = 4
+				c.CleanupOptions = CleanupLinks | CleanupEmojis
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.data), tt.format)
+			require.NoError(t, err)
+
+			want := tt.base
+			if tt.want != nil {
+				tt.want(&want)
+			}
+			assert.Equal(t, want, config)
+		})
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"Unknown JSON field", FormatJSON, `{"context_sise": 4}`},
+		{"Unknown YAML field", FormatYAML, "context_sise: 4\n"},
+		{"Unknown preset", FormatJSON, `{"preset": "fastest"}`},
+		{"Unknown quick yield mode", FormatYAML, "quick_yield_mode: sometimes\n"},
+		{"Unknown cleanup flag", FormatJSON, `{"cleanup_options": "markdown"}`},
+		{"Unknown format", "toml", `context_size = 4`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data), tt.format)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "splitter.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("preset: high-quality\ncontext_size: 20\n"), 0o644))
+
+	t.Run("File", func(t *testing.T) {
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+
+		want, err := Preset("high-quality")
+		require.NoError(t, err)
+		want.ContextSize = 20
+		assert.Equal(t, want, config)
+	})
+
+	t.Run("Environment overrides", func(t *testing.T) {
+		t.Setenv("STREAM2SENTENCE_PRESET", "low-latency")
+		t.Setenv("STREAM2SENTENCE_MINIMUM_SENTENCE_LENGTH", "5")
+		t.Setenv("STREAM2SENTENCE_QUICK_YIELD_MODE", "first")
+		t.Setenv("STREAM2SENTENCE_CLEANUP_OPTIONS", "none")
+
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+
+		want, err := Preset("low-latency")
+		require.NoError(t, err)
+		want.ContextSize = 20
+		want.MinimumSentenceLength = 5
+		want.QuickYieldMode = QuickYieldFirstFragment
+		want.CleanupOptions = 0
+		assert.Equal(t, want, config)
+	})
+
+	t.Run("Environment only", func(t *testing.T) {
+		t.Setenv("STREAM2SENTENCE_CONTEXT_SIZE", "8")
+
+		config, err := LoadConfig("")
+		require.NoError(t, err)
+
+		want := DefaultConfig()
+		want.ContextSize = 8
+		assert.Equal(t, want, config)
+	})
+
+	t.Run("Invalid environment", func(t *testing.T) {
+		t.Setenv("STREAM2SENTENCE_CONTEXT_SIZE", "eight")
+
+		_, err := LoadConfig(path)
+		assert.ErrorContains(t, err, "STREAM2SENTENCE_CONTEXT_SIZE")
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		t.Setenv("STREAM2SENTENCE_CONTEXT_SIZE", "-1")
+
+		_, err := LoadConfig(path)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("Unknown extension", func(t *testing.T) {
+		_, err := LoadConfig(filepath.Join(dir, "splitter.toml"))
+		assert.ErrorContains(t, err, ".toml")
+	})
+}
+
+func TestPresets(t *testing.T) {
+	for _, name := range PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			config, err := Preset(name)
+			require.NoError(t, err)
+			assert.NoError(t, config.Validate())
+		})
+	}
+
+	_, err := Preset("fastest")
+	assert.Error(t, err)
+}
+
+func TestPresetAliases(t *testing.T) {
+	for alias, want := range map[string]SentenceSplitterConfig{
+		"low-latency":      VoiceAssistantConfig(),
+		"high-quality":     NarrationConfig(),
+		"document-reading": NarrationConfig(),
+	} {
+		config, err := Preset(alias)
+		require.NoError(t, err)
+		assert.Equal(t, want, config, alias)
+	}
+}
@@ -0,0 +1,172 @@
+package stream2sentence
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configErrorFields returns the fields of all ConfigErrors joined in err
+func configErrorFields(err error) []string {
+	var fields []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			var configErr *ConfigError
+			if errors.As(e, &configErr) {
+				fields = append(fields, configErr.Field)
+			}
+		}
+	}
+	return fields
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*SentenceSplitterConfig)
+		fields []string
+	}{
+		{
+			name:   "Default config",
+			modify: func(*SentenceSplitterConfig) {},
+		},
+		{
+			name: "Negative lengths",
+			modify: func(c *SentenceSplitterConfig) {
+				c.ContextSize = -1
+				c.MinimumSentenceLength = -2
+				c.MinimumFirstFragmentLength = -3
+			},
+			fields: []string{"ContextSize", "MinimumSentenceLength", "MinimumFirstFragmentLength"},
+		},
+		{
+			name: "Unknown quick yield mode and cleanup flags",
+			modify: func(c *SentenceSplitterConfig) {
+				c.QuickYieldMode = 7
+				c.CleanupOptions = CleanupLinks | 1<<10
+			},
+			fields: []string{"QuickYieldMode", "CleanupOptions"},
+		},
+		{
+			name: "Empty delimiters",
+			modify: func(c *SentenceSplitterConfig) {
+				c.SentenceFragmentDelimiters = ""
+				c.FullSentenceDelimiters = ""
+			},
+			fields: []string{"SentenceFragmentDelimiters", "FullSentenceDelimiters"},
+		},
+		{
+			name: "Full delimiters not a subset",
+			modify: func(c *SentenceSplitterConfig) {
+				c.SentenceFragmentDelimiters = ",;"
+				c.FullSentenceDelimiters = ".;"
+			},
+			fields: []string{"FullSentenceDelimiters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+
+			err := config.Validate()
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			assert.Equal(t, tt.fields, configErrorFields(err))
+		})
+	}
+}
+
+func TestConfigErrorMessage(t *testing.T) {
+	config := DefaultConfig()
+	config.SentenceFragmentDelimiters = ","
+	config.FullSentenceDelimiters = ".!"
+
+	assert.EqualError(t, config.Validate(),
+		`stream2sentence: invalid FullSentenceDelimiters: '.', '!' not in SentenceFragmentDelimiters`)
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	observer := &recordingObserver{}
+
+	// A zero-value config becomes the default one
+	zero := SentenceSplitterConfig{Observer: observer}.WithDefaults()
+	expected := DefaultConfig()
+	expected.Observer = observer
+	assert.Equal(t, expected, zero)
+
+	// Otherwise only unset fields are filled in
+	partial := SentenceSplitterConfig{
+		MinimumSentenceLength: 25,
+		QuickYieldMode:        NoQuickYield,
+		CleanupOptions:        StripText,
+	}.WithDefaults()
+
+	assert.Equal(t, 25, partial.MinimumSentenceLength)
+	assert.Equal(t, NoQuickYield, partial.QuickYieldMode)
+	assert.Equal(t, StripText, partial.CleanupOptions)
+	assert.Equal(t, DefaultConfig().ContextSize, partial.ContextSize)
+	assert.Equal(t, DefaultConfig().SentenceFragmentDelimiters, partial.SentenceFragmentDelimiters)
+	assert.Equal(t, DefaultConfig().FullSentenceDelimiters, partial.FullSentenceDelimiters)
+}
+
+func TestConfigWithDefaultsFrom(t *testing.T) {
+	narration := NarrationConfig()
+	assert.Equal(t, narration, SentenceSplitterConfig{}.WithDefaultsFrom(narration))
+
+	partial := SentenceSplitterConfig{ContextSize: 5}.WithDefaultsFrom(narration)
+	assert.Equal(t, 5, partial.ContextSize)
+	assert.Equal(t, narration.MinimumSentenceLength, partial.MinimumSentenceLength)
+	assert.Equal(t, narration.SentenceFragmentDelimiters, partial.SentenceFragmentDelimiters)
+}
+
+func TestConfigWithTuning(t *testing.T) {
+	noQuickYield := NoQuickYield
+	runtime := SentenceSplitterConfig{
+		Metrics:   &Metrics{},
+		Observer:  &recordingObserver{},
+		Callbacks: Callbacks{OnFinish: func() error { return nil }},
+		Output:    OutputConfig{Buffer: 3},
+		Adaptive:  AdaptiveConfig{HighWater: 2, LaggingQuickYieldMode: &noQuickYield},
+	}
+	tuning := DefaultConfig()
+	merged := runtime.WithTuning(tuning)
+
+	// Every field is covered: tuning options are the serialized ones
+	mergedValue := reflect.ValueOf(merged)
+	fields := reflect.TypeOf(merged)
+	for i := range fields.NumField() {
+		field := fields.Field(i)
+		expected := reflect.ValueOf(tuning).Field(i)
+		if field.Tag.Get("json") == "-" {
+			expected = reflect.ValueOf(runtime).Field(i)
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			assert.Equal(t, fmt.Sprint(expected.Interface()), fmt.Sprint(mergedValue.Field(i).Interface()), field.Name)
+		} else {
+			assert.Equal(t, expected.Interface(), mergedValue.Field(i).Interface(), field.Name)
+		}
+	}
+}
+
+func TestNewSentenceSplitterE(t *testing.T) {
+	splitter, err := NewSentenceSplitterE(SentenceSplitterConfig{})
+	require.NoError(t, err)
+	splitter.Add("A zero config works like the default one. It really does.")
+	assert.Equal(t, []string{"A zero config works like the default one.", "It really does."}, drain(splitter, true))
+
+	splitter, err = NewSentenceSplitterE(SentenceSplitterConfig{ContextSize: -5})
+	assert.Nil(t, splitter)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, []string{"ContextSize"}, configErrorFields(err))
+}
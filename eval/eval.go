@@ -0,0 +1,226 @@
+// Package eval measures sentence boundary accuracy of a SentenceSplitter
+// configuration against annotated gold corpora
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// Record is one annotated document of a gold corpus
+type Record struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+
+	// Boundaries holds the rune offsets just past the last character of
+	// each reference sentence, in ascending order
+	Boundaries []int `json:"boundaries"`
+}
+
+// Result holds the accuracy and latency figures of an evaluation run
+type Result struct {
+	Documents      int
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+
+	Precision float64
+	Recall    float64
+	F1        float64
+
+	// FirstFragmentLatency is the mean number of input characters consumed
+	// before the first sentence fragment of a document was emitted
+	FirstFragmentLatency float64
+}
+
+// LoadCorpus reads a JSON Lines gold corpus with one Record per line
+func LoadCorpus(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// LoadCorpusFile reads a JSON Lines gold corpus from a file
+func LoadCorpusFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := LoadCorpus(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return records, nil
+}
+
+// Evaluate streams every record character by character through a splitter
+// built from config and scores the emitted boundaries against the reference.
+// The boundary at the end of a document is implied and not scored.
+func Evaluate(records []Record, config stream2sentence.SentenceSplitterConfig) Result {
+	var (
+		result       Result
+		totalLatency int
+	)
+
+	for _, record := range records {
+		sentences, latency := split(record.Text, config)
+		predicted := alignBoundaries([]rune(record.Text), sentences)
+
+		tp, fp, fn := compareBoundaries(scoredBoundaries(record.Text, record.Boundaries), scoredBoundaries(record.Text, predicted))
+		result.TruePositives += tp
+		result.FalsePositives += fp
+		result.FalseNegatives += fn
+
+		result.Documents++
+		totalLatency += latency
+	}
+
+	if result.TruePositives+result.FalsePositives > 0 {
+		result.Precision = float64(result.TruePositives) / float64(result.TruePositives+result.FalsePositives)
+	}
+
+	if result.TruePositives+result.FalseNegatives > 0 {
+		result.Recall = float64(result.TruePositives) / float64(result.TruePositives+result.FalseNegatives)
+	}
+
+	if result.Precision+result.Recall > 0 {
+		result.F1 = 2 * result.Precision * result.Recall / (result.Precision + result.Recall)
+	}
+
+	if result.Documents > 0 {
+		result.FirstFragmentLatency = float64(totalLatency) / float64(result.Documents)
+	}
+
+	return result
+}
+
+// split feeds text one character at a time and returns the emitted sentences
+// along with the number of characters consumed before the first one
+func split(text string, config stream2sentence.SentenceSplitterConfig) ([]string, int) {
+	var (
+		sentences []string
+		consumed  int
+		latency   = -1
+	)
+
+	splitter := stream2sentence.NewSentenceSplitter(config)
+	for _, char := range text {
+		consumed++
+		splitter.Add(string(char))
+		for sentence := range splitter.Stream() {
+			sentences = append(sentences, sentence)
+		}
+
+		if latency < 0 && len(sentences) > 0 {
+			latency = consumed
+		}
+	}
+
+	for sentence := range splitter.Flush() {
+		sentences = append(sentences, sentence)
+	}
+
+	if latency < 0 {
+		latency = consumed
+	}
+
+	return sentences, latency
+}
+
+// alignBoundaries maps emitted sentences back onto the source text and
+// returns the rune offset just past the last character of each sentence.
+// Whitespace is ignored and characters removed by cleanup are skipped.
+func alignBoundaries(text []rune, sentences []string) []int {
+	var (
+		boundaries []int
+		cursor     int
+	)
+
+	for _, sentence := range sentences {
+		end := -1
+		for _, char := range sentence {
+			if unicode.IsSpace(char) {
+				continue
+			}
+
+			for pos := cursor; pos < len(text); pos++ {
+				if text[pos] == char {
+					cursor = pos + 1
+					end = cursor
+					break
+				}
+			}
+		}
+
+		if end >= 0 {
+			boundaries = append(boundaries, end)
+		}
+	}
+
+	return boundaries
+}
+
+// scoredBoundaries drops boundaries at or past the last non-space character,
+// since every splitter trivially ends a sentence there
+func scoredBoundaries(text string, boundaries []int) map[int]bool {
+	runes := []rune(text)
+	end := len(runes)
+	for end > 0 && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+
+	scored := make(map[int]bool)
+	for _, boundary := range boundaries {
+		if boundary < end {
+			scored[boundary] = true
+		}
+	}
+	return scored
+}
+
+// compareBoundaries counts true positives, false positives and false negatives
+func compareBoundaries(reference, predicted map[int]bool) (tp, fp, fn int) {
+	for boundary := range predicted {
+		if reference[boundary] {
+			tp++
+		} else {
+			fp++
+		}
+	}
+
+	for boundary := range reference {
+		if !predicted[boundary] {
+			fn++
+		}
+	}
+
+	return tp, fp, fn
+}
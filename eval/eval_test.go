@@ -0,0 +1,125 @@
+package eval
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+func TestLoadCorpus(t *testing.T) {
+	input := `{"id": "a", "text": "One. Two.", "boundaries": [4, 9]}
+
+{"id": "b", "text": "Three.", "boundaries": [6]}
+`
+
+	records, err := LoadCorpus(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []Record{
+		{ID: "a", Text: "One. Two.", Boundaries: []int{4, 9}},
+		{ID: "b", Text: "Three.", Boundaries: []int{6}},
+	}, records)
+
+	_, err = LoadCorpus(strings.NewReader("{\"id\": \"a\"}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestAlignBoundaries(t *testing.T) {
+	text := []rune("First sentence.\n  Second one, with 😀 emoji. Third.")
+
+	boundaries := alignBoundaries(text, []string{
+		"First sentence.",
+		"Second one, with emoji.",
+		"Third.",
+	})
+
+	assert.Equal(t, []int{15, 43, 50}, boundaries)
+}
+
+func TestCompareBoundaries(t *testing.T) {
+	reference := map[int]bool{10: true, 20: true, 30: true}
+	predicted := map[int]bool{10: true, 25: true, 30: true}
+
+	tp, fp, fn := compareBoundaries(reference, predicted)
+	assert.Equal(t, 2, tp)
+	assert.Equal(t, 1, fp)
+	assert.Equal(t, 1, fn)
+}
+
+func TestEvaluate(t *testing.T) {
+	records := []Record{
+		{
+			ID:         "simple",
+			Text:       "This is the first sentence. This is the second sentence.",
+			Boundaries: []int{27, 56},
+		},
+	}
+
+	result := Evaluate(records, stream2sentence.DefaultConfig())
+	assert.Equal(t, 1, result.Documents)
+	assert.Equal(t, 1, result.TruePositives)
+	assert.Equal(t, 0, result.FalsePositives)
+	assert.Equal(t, 0, result.FalseNegatives)
+	assert.Equal(t, 1.0, result.F1)
+	assert.Equal(t, 27.0, result.FirstFragmentLatency)
+}
+
+func TestEvaluateGoldCorpora(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.jsonl"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			records, err := LoadCorpusFile(path)
+			require.NoError(t, err)
+			require.NotEmpty(t, records)
+
+			for _, record := range records {
+				runes := []rune(record.Text)
+				require.NotEmpty(t, record.Boundaries, record.ID)
+				assert.Equal(t, len(runes), record.Boundaries[len(record.Boundaries)-1], record.ID)
+			}
+
+			result := Evaluate(records, stream2sentence.DefaultConfig())
+			assert.Equal(t, len(records), result.Documents)
+			assert.Positive(t, result.Recall)
+		})
+	}
+}
+
+// BenchmarkPresets reports the accuracy and first fragment latency of every
+// configuration preset on the gold corpora; the figures quoted in the preset
+// documentation come from here
+func BenchmarkPresets(b *testing.B) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.jsonl"))
+	require.NoError(b, err)
+
+	var records []Record
+	for _, path := range paths {
+		corpus, err := LoadCorpusFile(path)
+		require.NoError(b, err)
+		records = append(records, corpus...)
+	}
+
+	for _, name := range stream2sentence.PresetNames() {
+		config, err := stream2sentence.Preset(name)
+		require.NoError(b, err)
+
+		b.Run(name, func(b *testing.B) {
+			var result Result
+			for b.Loop() {
+				result = Evaluate(records, config)
+			}
+
+			b.ReportMetric(result.Precision, "precision")
+			b.ReportMetric(result.Recall, "recall")
+			b.ReportMetric(result.F1, "F1")
+			b.ReportMetric(result.FirstFragmentLatency, "first-fragment-chars")
+		})
+	}
+}
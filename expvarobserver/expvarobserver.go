@@ -0,0 +1,78 @@
+// Package expvarobserver publishes stream2sentence splitter activity as
+// expvar counters
+package expvarobserver
+
+import (
+	"expvar"
+	"unicode/utf8"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// Counter names published in the map
+const (
+	Chunks          = "chunks"
+	ChunkChars      = "chunk_chars"
+	Sentences       = "sentences"
+	SentenceChars   = "sentence_chars"
+	Flushes         = "flushes"
+	ForcedBreaks    = "forced_breaks"
+	CleanupsApplied = "cleanups_applied"
+	Drops           = "drops"
+)
+
+// Observer counts splitter activity in an expvar.Map
+type Observer struct {
+	vars *expvar.Map
+}
+
+var _ stream2sentence.Observer = (*Observer)(nil)
+
+// New returns an Observer whose counters are published under name. Like
+// expvar.NewMap it panics if name is already registered.
+func New(name string) *Observer {
+	return NewWithMap(expvar.NewMap(name))
+}
+
+// NewWithMap returns an Observer that records counters into vars without
+// publishing it
+func NewWithMap(vars *expvar.Map) *Observer {
+	return &Observer{vars: vars}
+}
+
+// Map returns the map holding the counters
+func (o *Observer) Map() *expvar.Map {
+	return o.vars
+}
+
+// OnChunk counts the chunk and its characters
+func (o *Observer) OnChunk(chunk string) {
+	o.vars.Add(Chunks, 1)
+	o.vars.Add(ChunkChars, int64(utf8.RuneCountInString(chunk)))
+}
+
+// OnSentence counts the sentence and its characters
+func (o *Observer) OnSentence(sentence string) {
+	o.vars.Add(Sentences, 1)
+	o.vars.Add(SentenceChars, int64(utf8.RuneCountInString(sentence)))
+}
+
+// OnFlush counts the flush
+func (o *Observer) OnFlush(string) {
+	o.vars.Add(Flushes, 1)
+}
+
+// OnForcedBreak counts the forced break
+func (o *Observer) OnForcedBreak(string) {
+	o.vars.Add(ForcedBreaks, 1)
+}
+
+// OnCleanupApplied counts the sentence changed by cleanup
+func (o *Observer) OnCleanupApplied(string, string, stream2sentence.CleanupFlags) {
+	o.vars.Add(CleanupsApplied, 1)
+}
+
+// OnDrop counts the dropped sentence
+func (o *Observer) OnDrop(string) {
+	o.vars.Add(Drops, 1)
+}
@@ -0,0 +1,55 @@
+package expvarobserver
+
+import (
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+func counter(o *Observer, name string) int64 {
+	if v, ok := o.Map().Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestObserverCounters(t *testing.T) {
+	observer := NewWithMap(new(expvar.Map))
+
+	config := stream2sentence.DefaultConfig()
+	config.Observer = observer
+
+	chunks := []string{"Hello there, ", "this is a test 😀. ", "Another sentence follows here. ", "Done."}
+	var sentences []string
+	for sentence := range stream2sentence.GenerateSentencesFromSlice(chunks, stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	}) {
+		sentences = append(sentences, sentence)
+	}
+
+	assert.Equal(t, int64(len(chunks)), counter(observer, Chunks))
+	assert.Equal(t, int64(len(sentences)), counter(observer, Sentences))
+	assert.Equal(t, int64(1), counter(observer, Flushes))
+	assert.Equal(t, int64(1), counter(observer, ForcedBreaks))
+	assert.Equal(t, int64(1), counter(observer, CleanupsApplied))
+	assert.Positive(t, counter(observer, ChunkChars))
+	assert.Positive(t, counter(observer, SentenceChars))
+}
+
+// published numbers the names published by tests, as expvar names cannot
+// be reused when tests run more than once
+var published atomic.Int64
+
+func TestNewPublishes(t *testing.T) {
+	name := fmt.Sprintf("stream2sentence_test_%d", published.Add(1))
+	observer := New(name)
+	observer.OnChunk("abc")
+
+	assert.Same(t, observer.Map(), expvar.Get(name))
+	assert.Equal(t, int64(3), counter(observer, ChunkChars))
+}
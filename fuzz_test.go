@@ -0,0 +1,168 @@
+package stream2sentence
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stripSpace removes all whitespace so texts can be compared modulo spacing
+func stripSpace(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// splitRunes splits text into chunks of at most size runes
+func splitRunes(text string, size int) []string {
+	if size <= 0 {
+		size = 1
+	}
+
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		n := min(size, len(runes))
+		chunks = append(chunks, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return chunks
+}
+
+// === Fuzz Tests ===
+
+func FuzzTokenizeSentencesWithDelimiters(f *testing.F) {
+	f.Add("This is a sentence. And here's another! Yet, there's more.", ".?!;:,\n…)]}。-")
+	f.Add("Dr. Smith went to U.S.A. He paid $12.50 for 1,234 items.", ".?!,")
+	f.Add("| Name | Age |\n|------|-----|\n| John. | 25, |", ".?!;:,\n|")
+	f.Add("这是第一句话。这是第二句话！", "。！")
+	f.Add("", ".")
+
+	f.Fuzz(func(t *testing.T, text string, delimiters string) {
+		if !utf8.ValidString(text) || !utf8.ValidString(delimiters) {
+			t.Skip()
+		}
+
+		sentences := TokenizeSentencesWithDelimiters(text, delimiters)
+
+		for i, sentence := range sentences {
+			if sentence == "" {
+				t.Fatalf("sentence %d is empty", i)
+			}
+		}
+
+		if got, want := stripSpace(strings.Join(sentences, "")), stripSpace(text); got != want {
+			t.Fatalf("tokenized text %q does not match input %q", got, want)
+		}
+	})
+}
+
+func FuzzIsInsideTableCell(f *testing.F) {
+	f.Add("| A | B |\n|---|---|\n| 1. | 2, |", 12)
+	f.Add("no table here.", 3)
+	f.Add("|", 0)
+	f.Add("\n\n|a|b|\n", -5)
+
+	f.Fuzz(func(t *testing.T, text string, pos int) {
+		runes := []rune(text)
+		if len(runes) == 0 {
+			t.Skip()
+		}
+
+		if pos < 0 {
+			pos = -pos
+		}
+		isInsideTableCell(runes, pos%len(runes))
+	})
+}
+
+func FuzzCleanText(f *testing.F) {
+	f.Add("  Visit https://example.com now 😀  ", int(CleanupAll))
+	f.Add("| A | B |\n|---|---|\n\n\n\nText after table.", int(CleanupTable))
+	f.Add("\t plain text \n", int(StripText))
+	f.Add("", 0)
+
+	f.Fuzz(func(t *testing.T, text string, flags int) {
+		if !utf8.ValidString(text) {
+			t.Skip()
+		}
+
+		cleanupFlags := CleanupFlags(flags) & CleanupAll
+		result := CleanText(text, cleanupFlags)
+
+		if !utf8.ValidString(result) {
+			t.Fatalf("cleaned text %q is not valid UTF-8", result)
+		}
+
+		// Without content-removing flags only whitespace may change
+		if cleanupFlags&^StripText == 0 && stripSpace(result) != stripSpace(text) {
+			t.Fatalf("cleaned text %q does not match input %q", result, text)
+		}
+
+		// Cleaning again removes nothing more
+		if again := CleanText(result, cleanupFlags); stripSpace(again) != stripSpace(result) {
+			t.Fatalf("cleaning %q again gave %q", result, again)
+		}
+	})
+}
+
+func FuzzSentenceSplitter(f *testing.F) {
+	f.Add("This is a sentence. And here's another! Yet, there's more. This ends now.", 1, int(StripText))
+	f.Add("Dr. Smith went to U.S.A. He met Mr. Johnson.", 3, int(StripText))
+	f.Add("The price is $12.50 per item. Total came to 1,234.56 dollars.", 7, int(CleanupAll))
+	f.Add("Hello 😀 world! 🎉 Visit https://example.com for more.", 2, int(CleanupAll))
+	f.Add("这是第一句话。这是第二句话！还有第三句话？最后一句话。", 4, int(StripText))
+	f.Add("Intro.\n\n| Name | Age |\n|------|-----|\n| John | 25  |\n\nOutro.", 5, int(CleanupTable))
+	f.Add("😀", 1, int(CleanupEmojis))
+
+	f.Fuzz(func(t *testing.T, text string, chunkSize int, flags int) {
+		if !utf8.ValidString(text) {
+			t.Skip()
+		}
+
+		config := DefaultConfig()
+		config.CleanupOptions = CleanupFlags(flags) & CleanupAll
+		config.Metrics = &Metrics{}
+		splitter := NewSentenceSplitter(config)
+
+		var sentences []string
+		for _, chunk := range splitRunes(text, chunkSize%64) {
+			splitter.Add(chunk)
+			for sentence := range splitter.Stream() {
+				sentences = append(sentences, sentence)
+			}
+		}
+		for sentence := range splitter.Flush() {
+			sentences = append(sentences, sentence)
+		}
+
+		for i, sentence := range sentences {
+			if sentence == "" {
+				t.Fatalf("sentence %d is empty", i)
+			}
+		}
+
+		if splitter.buffer.Len() != 0 || splitter.inputBuffer.Len() != 0 {
+			t.Fatalf("flush left %q in the buffer", splitter.buffer.String())
+		}
+
+		if stats := config.Metrics.Stats(); stats.Sentences != len(sentences) {
+			t.Fatalf("metrics recorded %d sentences, want %d", stats.Sentences, len(sentences))
+		}
+
+		// Cleaning sentence by sentence removes what cleaning the whole text
+		// would. Table lines are told apart by their whole content, which a
+		// sentence cut from a line lacks, so table cleanup is not compared.
+		if config.CleanupOptions.HasFlag(CleanupTable) {
+			return
+		}
+		want := stripSpace(CleanText(strings.ReplaceAll(text, "\x00", ""), config.CleanupOptions))
+		if got := stripSpace(strings.Join(sentences, "")); got != want {
+			t.Fatalf("split text %q does not match input %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+module github.com/txt-dot/stream2sentence/grpcserver
+
+go 1.24.0
+
+require (
+	github.com/stretchr/testify v1.8.4
+	github.com/txt-dot/stream2sentence v0.1.0
+	github.com/txt-dot/stream2sentence/splitterpb v0.1.0
+	google.golang.org/grpc v1.80.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.49.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260120221211-b8f7ae30c516 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/txt-dot/stream2sentence => ../
+
+replace github.com/txt-dot/stream2sentence/splitterpb => ../splitterpb
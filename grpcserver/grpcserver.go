@@ -0,0 +1,333 @@
+// Package grpcserver implements the Splitter gRPC service defined in
+// splitterpb. Every Split stream gets its own SentenceSplitter, so clients
+// in any language can stream text in and sentences out.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/splitterpb"
+)
+
+// Default limits of a Config
+const (
+	DefaultMaxInputBytes    = 16 << 20
+	DefaultMaxBufferedBytes = 64 << 10
+	DefaultMaxLength        = 1024
+)
+
+// errInputTooLarge ends a stream whose input exceeds MaxInputBytes
+var errInputTooLarge = status.Error(codes.ResourceExhausted, "input exceeds the size limit")
+
+// Config holds the service options
+type Config struct {
+	// Splitter is the configuration of every stream's splitter. Clients may
+	// override its tuning options with a Config message. Callbacks are not
+	// used.
+	Splitter stream2sentence.SentenceSplitterConfig
+
+	// MaxInputBytes caps the text of a stream. Zero means
+	// DefaultMaxInputBytes and a negative value no limit.
+	MaxInputBytes int64
+
+	// MaxBufferedBytes caps the text received since the last sentence.
+	// Once more has arrived, the buffered text is flushed as if the client
+	// had sent a flush. Zero means DefaultMaxBufferedBytes and a negative
+	// value no limit.
+	MaxBufferedBytes int
+
+	// MaxLength caps the context_size, minimum_sentence_length and
+	// minimum_first_fragment_length clients may set; larger values are
+	// clamped. Zero means DefaultMaxLength and a negative value no limit.
+	MaxLength int
+
+	// UpstreamErrorPolicy decides whether text buffered when a client's
+	// stream breaks off is still sent
+	UpstreamErrorPolicy stream2sentence.UpstreamErrorPolicy
+}
+
+// Service implements splitterpb.SplitterServer. Register it with
+// splitterpb.RegisterSplitterServer.
+type Service struct {
+	splitterpb.UnimplementedSplitterServer
+
+	config Config
+}
+
+var _ splitterpb.SplitterServer = (*Service)(nil)
+
+// New creates a Service from the given configuration
+func New(config Config) *Service {
+	config.Splitter.Callbacks = stream2sentence.Callbacks{}
+	if config.MaxInputBytes == 0 {
+		config.MaxInputBytes = DefaultMaxInputBytes
+	}
+	if config.MaxBufferedBytes == 0 {
+		config.MaxBufferedBytes = DefaultMaxBufferedBytes
+	}
+	if config.MaxLength == 0 {
+		config.MaxLength = DefaultMaxLength
+	}
+	return &Service{config: config}
+}
+
+// Split implements the Split RPC. It ends once the client has closed its
+// side and the remaining text is sent, or with the stream's context.
+func (s *Service) Split(stream splitterpb.Splitter_SplitServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	config := stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: s.config.Splitter,
+		UpstreamErrorPolicy:    s.config.UpstreamErrorPolicy,
+	}
+	if overrides := first.GetConfig(); overrides != nil {
+		config.SentenceSplitterConfig, err = s.applyConfig(config.SentenceSplitterConfig, overrides)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		first = nil
+	}
+
+	var (
+		text     = locator{limit: s.config.MaxBufferedBytes}
+		flushing bool
+	)
+
+	source := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		request := first
+		first = nil
+
+		if request == nil {
+			var err error
+			if request, err = stream.Recv(); err != nil {
+				return "", err
+			}
+		}
+
+		switch payload := request.Payload.(type) {
+		case *splitterpb.SplitRequest_Text:
+			text.add(payload.Text)
+			return payload.Text, nil
+		case *splitterpb.SplitRequest_Flush:
+			return "", stream2sentence.ErrBoundary
+		case *splitterpb.SplitRequest_Config:
+			return "", status.Error(codes.InvalidArgument, "config is only accepted as the first message")
+		default:
+			return "", status.Error(codes.InvalidArgument, "empty request")
+		}
+	})
+
+	limited, sent := s.limitSource(source)
+
+	// Sentences are emitted right after the chunk that completed them, so
+	// those following a flush, the end of the stream or an error were
+	// flushed rather than detected
+	tracked := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		chunk, err := limited.Next(ctx)
+		flushing = err != nil
+		return chunk, err
+	})
+
+	for sentence, err := range stream2sentence.SentencesFromSource(ctx, tracked, config) {
+		if err != nil {
+			return statusError(ctx, err)
+		}
+		sent()
+
+		start, end, located := text.locate(sentence.Text)
+		err = stream.Send(&splitterpb.SplitResponse{
+			Text:     sentence.Text,
+			Index:    int32(sentence.Index),
+			Fragment: sentence.Fragment,
+			Start:    start,
+			End:      end,
+			Located:  located,
+			Flushed:  flushing,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statusError converts an error that ended a stream to a gRPC status error
+func statusError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	var upstream *stream2sentence.UpstreamError
+	if errors.As(err, &upstream) {
+		err = upstream.Err
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// limitSource ends the input of source with an error once it exceeds
+// MaxInputBytes, and forces a sentence boundary once MaxBufferedBytes have
+// arrived since the last sentence. The returned function must be called for
+// every sentence.
+func (s *Service) limitSource(source stream2sentence.Source) (stream2sentence.Source, func()) {
+	var input int64
+	buffered := 0
+
+	limited := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		if limit := s.config.MaxBufferedBytes; limit > 0 && buffered > limit {
+			buffered = 0
+			return "", stream2sentence.ErrBoundary
+		}
+
+		chunk, err := source.Next(ctx)
+		input += int64(len(chunk))
+		buffered += len(chunk)
+
+		if limit := s.config.MaxInputBytes; limit > 0 && input > limit {
+			return "", errInputTooLarge
+		}
+		if errors.Is(err, stream2sentence.ErrBoundary) {
+			buffered = 0
+		}
+		return chunk, err
+	})
+
+	return limited, func() { buffered = 0 }
+}
+
+// applyConfig overlays the options set in overrides onto config. Lengths
+// are clamped to MaxLength.
+func (s *Service) applyConfig(config stream2sentence.SentenceSplitterConfig, overrides *splitterpb.Config) (stream2sentence.SentenceSplitterConfig, error) {
+	if overrides.Preset != "" {
+		preset, err := stream2sentence.Preset(overrides.Preset)
+		if err != nil {
+			return config, err
+		}
+		config = config.WithTuning(preset)
+	}
+
+	if overrides.ContextSize != nil {
+		config.ContextSize = s.clampLength(*overrides.ContextSize)
+	}
+	if overrides.MinimumSentenceLength != nil {
+		config.MinimumSentenceLength = s.clampLength(*overrides.MinimumSentenceLength)
+	}
+	if overrides.MinimumFirstFragmentLength != nil {
+		config.MinimumFirstFragmentLength = s.clampLength(*overrides.MinimumFirstFragmentLength)
+	}
+	if overrides.SentenceFragmentDelimiters != nil {
+		config.SentenceFragmentDelimiters = *overrides.SentenceFragmentDelimiters
+	}
+	if overrides.FullSentenceDelimiters != nil {
+		config.FullSentenceDelimiters = *overrides.FullSentenceDelimiters
+	}
+	if overrides.Cleanup != nil {
+		if err := config.CleanupOptions.UnmarshalText([]byte(*overrides.Cleanup)); err != nil {
+			return config, err
+		}
+	}
+
+	switch overrides.QuickYieldMode {
+	case splitterpb.QuickYieldMode_QUICK_YIELD_MODE_UNSPECIFIED:
+	case splitterpb.QuickYieldMode_QUICK_YIELD_MODE_NONE:
+		config.QuickYieldMode = stream2sentence.NoQuickYield
+	case splitterpb.QuickYieldMode_QUICK_YIELD_MODE_FIRST_FRAGMENT:
+		config.QuickYieldMode = stream2sentence.QuickYieldFirstFragment
+	case splitterpb.QuickYieldMode_QUICK_YIELD_MODE_ALL_FRAGMENTS:
+		config.QuickYieldMode = stream2sentence.QuickYieldAllFragments
+	default:
+		return config, errors.New("unknown quick yield mode " + overrides.QuickYieldMode.String())
+	}
+
+	return config, config.Validate()
+}
+
+// clampLength converts a length set by a client, clamped to MaxLength
+func (s *Service) clampLength(length int32) int {
+	if limit := s.config.MaxLength; limit > 0 {
+		return min(int(length), limit)
+	}
+	return int(length)
+}
+
+// locator finds emitted sentences in the received text. Only the text after
+// the last located sentence is kept, and of the text received before the
+// latest chunk at most limit bytes if limit is positive, so sentences that
+// cannot be located do not keep their text around.
+type locator struct {
+	text   string
+	offset int64
+	limit  int
+}
+
+// add appends a received chunk, dropping the oldest text beyond the limit
+func (l *locator) add(chunk string) {
+	if excess := len(l.text) - l.limit; l.limit > 0 && excess > 0 {
+		for excess < len(l.text) && !utf8.RuneStart(l.text[excess]) {
+			excess++
+		}
+		l.text = l.text[excess:]
+		l.offset += int64(excess)
+	}
+	l.text += chunk
+}
+
+// locate returns the byte offsets of sentence in the received text and
+// consumes the text up to its end. Sentences are matched rune by rune,
+// ignoring whitespace and skipping text that cleanup removed. If a rune
+// cannot be found, e.g. because cleanup replaced it, nothing is consumed
+// and both offsets equal the end of the previous sentence.
+func (l *locator) locate(sentence string) (start, end int64, ok bool) {
+	first, pos := -1, 0
+
+	for _, r := range sentence {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		at := -1
+		for pos < len(l.text) {
+			c, size := utf8.DecodeRuneInString(l.text[pos:])
+			pos += size
+			if c == r {
+				at = pos - size
+				break
+			}
+		}
+		if at < 0 {
+			return l.offset, l.offset, false
+		}
+
+		if first < 0 {
+			first = at
+		}
+	}
+
+	if first < 0 {
+		return l.offset, l.offset, false
+	}
+
+	start, end = l.offset+int64(first), l.offset+int64(pos)
+	l.text = l.text[pos:]
+	l.offset = end
+	return start, end, true
+}
@@ -0,0 +1,341 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/splitterpb"
+)
+
+// startService serves a Service over an in-memory connection and returns a
+// client for it
+func startService(t *testing.T, config Config) splitterpb.SplitterClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	splitterpb.RegisterSplitterServer(server, New(config))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return splitterpb.NewSplitterClient(conn)
+}
+
+// text returns a request carrying a text chunk
+func text(chunk string) *splitterpb.SplitRequest {
+	return &splitterpb.SplitRequest{Payload: &splitterpb.SplitRequest_Text{Text: chunk}}
+}
+
+// flush returns a flush request
+func flush() *splitterpb.SplitRequest {
+	return &splitterpb.SplitRequest{Payload: &splitterpb.SplitRequest_Flush{Flush: &splitterpb.Flush{}}}
+}
+
+// configure returns a config request
+func configure(config *splitterpb.Config) *splitterpb.SplitRequest {
+	return &splitterpb.SplitRequest{Payload: &splitterpb.SplitRequest_Config{Config: config}}
+}
+
+// receiveAll reads responses until the stream ends and returns them with the
+// final error, nil if the stream ended normally
+func receiveAll(stream splitterpb.Splitter_SplitClient) ([]*splitterpb.SplitResponse, error) {
+	var responses []*splitterpb.SplitResponse
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return responses, nil
+		}
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, response)
+	}
+}
+
+// === Split Tests ===
+
+func TestSplit(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+	ctx := context.Background()
+
+	stream, err := client.Split(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(text("This is the first sentence. And ")))
+
+	// The first sentence arrives before the client closes its side
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&splitterpb.SplitResponse{
+		Text:     "This is the first sentence.",
+		Fragment: true,
+		Start:    0,
+		End:      27,
+		Located:  true,
+	}, response), response.String())
+
+	require.NoError(t, stream.Send(text("here comes the second one. And that is all")))
+	require.NoError(t, stream.CloseSend())
+
+	responses, err := receiveAll(stream)
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+
+	assert.Equal(t, "And here comes the second one.", responses[0].Text)
+	assert.Equal(t, int32(1), responses[0].Index)
+	assert.Equal(t, []int64{28, 58}, []int64{responses[0].Start, responses[0].End})
+	assert.False(t, responses[0].Flushed)
+
+	assert.Equal(t, "And that is all", responses[1].Text)
+	assert.Equal(t, []int64{59, 74}, []int64{responses[1].Start, responses[1].End})
+	assert.True(t, responses[1].Flushed)
+}
+
+func TestSplitConfig(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+
+	stream, err := client.Split(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(configure(&splitterpb.Config{
+		Preset:                "narration",
+		MinimumSentenceLength: proto.Int32(5),
+		QuickYieldMode:        splitterpb.QuickYieldMode_QUICK_YIELD_MODE_NONE,
+		Cleanup:               proto.String("none"),
+	})))
+	require.NoError(t, stream.Send(text("Hi, there. How are you?")))
+	require.NoError(t, stream.CloseSend())
+
+	responses, err := receiveAll(stream)
+	require.NoError(t, err)
+
+	var sentences []string
+	for _, response := range responses {
+		sentences = append(sentences, response.Text)
+		assert.False(t, response.Fragment)
+	}
+	assert.Equal(t, []string{"Hi, there.", "How are you?"}, sentences)
+}
+
+func TestSplitFlush(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+
+	stream, err := client.Split(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(text("Let me think about")))
+	require.NoError(t, stream.Send(flush()))
+
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Let me think about", response.Text)
+	assert.True(t, response.Flushed)
+	assert.Equal(t, []int64{0, 18}, []int64{response.Start, response.End})
+
+	require.NoError(t, stream.CloseSend())
+	responses, err := receiveAll(stream)
+	require.NoError(t, err)
+	assert.Empty(t, responses)
+}
+
+func TestSplitCleanupOffsets(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+
+	stream, err := client.Split(context.Background())
+	require.NoError(t, err)
+
+	input := "See https://example.com for details. Then 😀 we are done here."
+	require.NoError(t, stream.Send(configure(&splitterpb.Config{Cleanup: proto.String("links,emojis")})))
+	require.NoError(t, stream.Send(text(input)))
+	require.NoError(t, stream.CloseSend())
+
+	responses, err := receiveAll(stream)
+	require.NoError(t, err)
+	require.NotEmpty(t, responses)
+
+	// Offsets span the original text, including the removed parts
+	last := responses[len(responses)-1]
+	assert.True(t, last.Located)
+	assert.Equal(t, int64(len(input)), last.End)
+	for _, response := range responses {
+		assert.True(t, response.Located, response.Text)
+	}
+	assert.Equal(t, int64(0), responses[0].Start)
+}
+
+func TestSplitInvalidConfig(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+
+	for _, config := range []*splitterpb.Config{
+		{Preset: "fastest"},
+		{ContextSize: proto.Int32(-1)},
+		{Cleanup: proto.String("sparkles")},
+		{QuickYieldMode: splitterpb.QuickYieldMode(42)},
+	} {
+		stream, err := client.Split(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(configure(config)))
+
+		_, err = receiveAll(stream)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), config.String())
+	}
+}
+
+func TestSplitLateConfig(t *testing.T) {
+	client := startService(t, Config{Splitter: stream2sentence.DefaultConfig()})
+
+	stream, err := client.Split(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(text("Hello")))
+	require.NoError(t, stream.Send(configure(&splitterpb.Config{})))
+
+	_, err = receiveAll(stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "first message")
+}
+
+func TestSplitLimits(t *testing.T) {
+	client := startService(t, Config{
+		Splitter:         stream2sentence.DefaultConfig(),
+		MaxInputBytes:    64,
+		MaxBufferedBytes: 16,
+	})
+
+	// Text without a sentence boundary is flushed once it exceeds the
+	// buffer cap, while the stream is still open
+	stream, err := client.Split(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(text("no boundary in sight ")))
+
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "no boundary in sight", response.Text)
+	assert.True(t, response.Flushed)
+	require.NoError(t, stream.CloseSend())
+	_, err = receiveAll(stream)
+	require.NoError(t, err)
+
+	// Input beyond the size limit ends the stream with an error
+	stream, err = client.Split(context.Background())
+	require.NoError(t, err)
+	for range 20 {
+		require.NoError(t, stream.Send(text("Word. ")))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	_, err = receiveAll(stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestOverridesClamped(t *testing.T) {
+	s := New(Config{Splitter: stream2sentence.DefaultConfig(), MaxLength: 100})
+
+	config, err := s.applyConfig(s.config.Splitter, &splitterpb.Config{
+		ContextSize:           proto.Int32(100000),
+		MinimumSentenceLength: proto.Int32(50),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 100, config.ContextSize)
+	assert.Equal(t, 50, config.MinimumSentenceLength)
+	assert.Equal(t, stream2sentence.DefaultConfig().MinimumFirstFragmentLength, config.MinimumFirstFragmentLength)
+}
+
+// === Cancellation Tests ===
+
+func TestSplitCancel(t *testing.T) {
+	ended := make(chan error, 1)
+	service := New(Config{Splitter: stream2sentence.DefaultConfig()})
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.StreamInterceptor(
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			err := handler(srv, ss)
+			ended <- err
+			return err
+		}))
+	splitterpb.RegisterSplitterServer(server, service)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := splitterpb.NewSplitterClient(conn).Split(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(text("A sentence that never ends")))
+
+	cancel()
+
+	_, err = receiveAll(stream)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	select {
+	case err := <-ended:
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	case <-time.After(5 * time.Second):
+		t.Fatal("server stream did not end after cancellation")
+	}
+}
+
+// === Locator Tests ===
+
+func TestLocator(t *testing.T) {
+	var l locator
+	l.add("  Grüße aus ")
+	l.add("Köln! Bis bald.")
+
+	start, end, ok := l.locate("Grüße aus Köln!")
+	assert.True(t, ok)
+	assert.Equal(t, "Grüße aus Köln!", "  Grüße aus Köln! Bis bald."[start:end])
+
+	// A sentence that does not occur leaves the text untouched
+	start, end, ok = l.locate("Auf Wiedersehen.")
+	assert.False(t, ok)
+	assert.Equal(t, end, start)
+
+	start, end, ok = l.locate("Bis bald.")
+	assert.True(t, ok)
+	assert.Equal(t, "Bis bald.", "  Grüße aus Köln! Bis bald."[start:end])
+}
+
+func TestLocatorLimit(t *testing.T) {
+	l := locator{limit: 8}
+	l.add("Grüße, ")
+	l.add("nicht gefunden ")
+	l.add("Ende.")
+
+	// Text that no sentence matched is dropped beyond the limit
+	assert.LessOrEqual(t, len(l.text), 8+len("Ende."))
+	start, end, ok := l.locate("Ende.")
+	assert.True(t, ok)
+	assert.Equal(t, "Ende.", "Grüße, nicht gefunden Ende."[start:end])
+}
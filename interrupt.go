@@ -0,0 +1,133 @@
+package stream2sentence
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInterrupted is returned by Feed and Finish when Interrupt stops them.
+// Channels returned by Stream and Flush are closed instead.
+var ErrInterrupted = errors.New("stream2sentence: interrupted")
+
+// Remainder is the text an interrupted splitter never delivered, in stream
+// order
+type Remainder struct {
+	// Sentences were emitted but not received by the consumer, including
+	// one whose delivery was cut off
+	Sentences []string
+
+	// Buffered is the text read from the input but not yet emitted
+	Buffered string
+
+	// Pending holds the chunks added but not yet read
+	Pending []string
+
+	// Dropped counts the sentences discarded under LagDropOldest since the
+	// stream started. They came before sentences the consumer received, so
+	// they are not part of Text.
+	Dropped int
+}
+
+// Text returns the undelivered text as a single string
+func (r Remainder) Text() string {
+	parts := append([]string(nil), r.Sentences...)
+	if rest := strings.TrimSpace(r.Buffered + strings.Join(r.Pending, "")); rest != "" {
+		parts = append(parts, rest)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Empty reports whether Text is empty. Dropped sentences are not counted.
+func (r Remainder) Empty() bool {
+	return len(r.Sentences) == 0 && strings.TrimSpace(r.Buffered) == "" && strings.TrimSpace(strings.Join(r.Pending, "")) == ""
+}
+
+// Interrupt stops emission, e.g. when the user barges in, and returns what
+// was not delivered: the sentences sitting unread in the channel returned by
+// Stream or Flush, the buffered text and the pending chunks. It waits for
+// running processing to stop, then leaves the splitter empty like Reset, so
+// it can start a new stream. Interrupt may be called from any goroutine, but
+// not from a callback: it waits for processing, including the running
+// callback, to stop, and would deadlock. A callback should return an error
+// to stop processing instead.
+func (s *SentenceSplitter) Interrupt() Remainder {
+	s.interruptMu.Lock()
+	defer s.interruptMu.Unlock()
+
+	s.signalMu.Lock()
+	s.interrupting.Store(true)
+	close(s.interrupted)
+	results := s.results
+	s.signalMu.Unlock()
+
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	var remainder Remainder
+
+	// Sentences still queued in the channel precede the one cut off
+drain:
+	for results != nil {
+		select {
+		case sentence, ok := <-results:
+			if !ok {
+				break drain
+			}
+			remainder.Sentences = append(remainder.Sentences, sentence)
+		default:
+			break drain
+		}
+	}
+	remainder.Sentences = append(remainder.Sentences, s.cutOff...)
+	remainder.Buffered = s.buffer.String()
+	remainder.Dropped = s.dropped
+
+	s.inputMu.Lock()
+	for element := s.inputBuffer.Front(); element != nil; element = element.Next() {
+		remainder.Pending = append(remainder.Pending, element.Value.(inputChunk).text)
+	}
+	s.inputBuffer.Init()
+	s.inputMu.Unlock()
+
+	s.clearBuffer()
+	s.cutOff = nil
+	s.err = nil
+	s.emitted = 0
+	s.consumed = 0
+	s.dropped = 0
+
+	s.signalMu.Lock()
+	s.interrupted = make(chan struct{})
+	s.interrupting.Store(false)
+	s.signalMu.Unlock()
+
+	return remainder
+}
+
+// interruptSignal returns a channel that is closed when Interrupt is called
+func (s *SentenceSplitter) interruptSignal() <-chan struct{} {
+	s.signalMu.Lock()
+	defer s.signalMu.Unlock()
+	return s.interrupted
+}
+
+// track records the result channel whose unread sentences Interrupt reports
+// and count towards the consumer's backlog
+func (s *SentenceSplitter) track(results chan string) {
+	s.signalMu.Lock()
+	defer s.signalMu.Unlock()
+	s.results = results
+	s.backlog = func() int { return len(results) }
+}
+
+// unread returns the unprocessed part of a chunk to the front of the input
+// buffer
+func (s *SentenceSplitter) unread(chunk inputChunk) {
+	if chunk.text == "" {
+		return
+	}
+
+	s.inputMu.Lock()
+	defer s.inputMu.Unlock()
+	s.inputBuffer.PushFront(chunk)
+}
@@ -0,0 +1,295 @@
+package stream2sentence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// numberedSentences returns n distinct sentences
+func numberedSentences(n int) []string {
+	sentences := make([]string, n)
+	for i := range sentences {
+		sentences[i] = fmt.Sprintf("This is sentence number %d of the story.", i+1)
+	}
+	return sentences
+}
+
+// words normalizes text for comparisons across sentence boundaries
+func words(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// === Interrupt Tests ===
+
+func TestInterruptPendingChunks(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+	splitter.Add("Hello there, ")
+	splitter.Add("how are you")
+
+	remainder := splitter.Interrupt()
+	assert.Empty(t, remainder.Sentences)
+	assert.Empty(t, remainder.Buffered)
+	assert.Equal(t, []string{"Hello there, ", "how are you"}, remainder.Pending)
+	assert.Equal(t, "Hello there, how are you", remainder.Text())
+
+	// The splitter is empty afterwards
+	assert.True(t, splitter.Interrupt().Empty())
+}
+
+func TestInterruptBuffered(t *testing.T) {
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	splitter := NewSentenceSplitter(config)
+
+	splitter.Add("The weather today is sunny and")
+	for range splitter.Stream() {
+	}
+
+	remainder := splitter.Interrupt()
+	assert.Empty(t, remainder.Sentences)
+	assert.Equal(t, "The weather today is sunny and", remainder.Buffered)
+	assert.Empty(t, remainder.Pending)
+}
+
+func TestInterruptStream(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	splitter := NewSentenceSplitter(config)
+
+	// A single chunk yields more sentences than the channel buffers, so
+	// the stream blocks in the middle of the chunk
+	text := strings.Join(numberedSentences(30), " ")
+	splitter.Add(text)
+
+	results := splitter.Stream()
+	first := <-results
+
+	remainder := splitter.Interrupt()
+	assert.NotEmpty(t, remainder.Sentences)
+	assert.NotEmpty(t, remainder.Pending, "rest of the chunk")
+
+	// The channel is closed without further sentences
+	for sentence := range results {
+		t.Errorf("sentence %q delivered after the interruption", sentence)
+	}
+
+	// Nothing is lost or duplicated
+	assert.Equal(t, words(text), words(first+" "+remainder.Text()))
+}
+
+func TestInterruptFlush(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	config.ContextSize = 1000
+	splitter := NewSentenceSplitter(config)
+
+	text := strings.Join(numberedSentences(20), " ")
+	splitter.Add(text)
+	for range splitter.Stream() {
+	}
+
+	results := splitter.Flush()
+	first := <-results
+
+	remainder := splitter.Interrupt()
+	for range results {
+	}
+	assert.Equal(t, words(text), words(first+" "+remainder.Text()))
+}
+
+func TestInterruptRestart(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+	splitter.Add(strings.Join(numberedSentences(30), " "))
+
+	results := splitter.Stream()
+	<-results
+	splitter.Interrupt()
+
+	splitter.Add("A new answer starts here. And it goes on for a while.")
+	var sentences []string
+	for sentence := range splitter.Stream() {
+		sentences = append(sentences, sentence)
+	}
+	for sentence := range splitter.Flush() {
+		sentences = append(sentences, sentence)
+	}
+	assert.Equal(t, "A new answer starts here. And it goes on for a while.", words(strings.Join(sentences, " ")))
+}
+
+// interruptInCallback returns a config whose OnSentence callback, on the
+// first sentence, starts an Interrupt on another goroutine and waits until
+// it is under way, as a barge-in during processing would. The remainder is
+// sent on the returned channel.
+func interruptInCallback(splitter **SentenceSplitter) (SentenceSplitterConfig, <-chan Remainder) {
+	remainders := make(chan Remainder, 1)
+	var started bool
+
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	config.Callbacks.OnSentence = func(Sentence) error {
+		if !started {
+			started = true
+			go func() { remainders <- (*splitter).Interrupt() }()
+			for !(*splitter).interrupting.Load() {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		return nil
+	}
+	return config, remainders
+}
+
+func TestInterruptFeed(t *testing.T) {
+	var splitter *SentenceSplitter
+	config, remainders := interruptInCallback(&splitter)
+	splitter = NewSentenceSplitter(config)
+
+	text := strings.Join(numberedSentences(5), " ")
+	err := splitter.Feed(text)
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	remainder := <-remainders
+	assert.Equal(t, words(text), words(numberedSentences(1)[0]+" "+remainder.Text()))
+	assert.NoError(t, splitter.Err())
+}
+
+func TestInterruptFinish(t *testing.T) {
+	var splitter *SentenceSplitter
+	config, remainders := interruptInCallback(&splitter)
+	config.ContextSize = 1000
+	finished := false
+	config.Callbacks.OnFinish = func() error {
+		finished = true
+		return nil
+	}
+	splitter = NewSentenceSplitter(config)
+
+	text := strings.Join(numberedSentences(5), " ")
+	require.NoError(t, splitter.Feed(text))
+	err := splitter.Finish()
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.False(t, finished)
+
+	remainder := <-remainders
+	assert.Equal(t, words(text), words(numberedSentences(1)[0]+" "+remainder.Text()))
+}
+
+func TestInterruptDuringStateChanges(t *testing.T) {
+	// Run with -race: Interrupt from another goroutine must not race with
+	// the consumer resetting, reconfiguring or migrating the splitter
+	splitter := NewSentenceSplitter(DefaultConfig())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			splitter.Interrupt()
+		}
+	}()
+
+	for range 100 {
+		splitter.Add("Half a ")
+		require.NoError(t, splitter.Reconfigure(DefaultConfig()))
+		data, err := splitter.Snapshot()
+		require.NoError(t, err)
+		require.NoError(t, splitter.Restore(data))
+		splitter.Reset()
+	}
+	<-done
+}
+
+func TestRemainderText(t *testing.T) {
+	remainder := Remainder{
+		Sentences: []string{"First one.", "Second one."},
+		Buffered:  "And the ",
+		Pending:   []string{"rest of", " it"},
+	}
+	assert.Equal(t, "First one. Second one. And the rest of it", remainder.Text())
+	assert.False(t, remainder.Empty())
+	assert.True(t, Remainder{Buffered: "  "}.Empty())
+}
+
+// === Tracked Generation Tests ===
+
+func TestGenerationInterrupt(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+
+	text := numberedSentences(30)
+	generator := make(chan string)
+	generation := GenerateSentencesTracked(context.Background(), generator, GenerateSentencesConfig{SentenceSplitterConfig: config})
+
+	go func() {
+		for _, sentence := range text {
+			select {
+			case generator <- sentence + " ":
+			case <-generation.finished:
+				return
+			}
+		}
+	}()
+
+	// The consumer speaks the first sentence and starts on the second
+	spoken := <-generation.Sentences()
+	generation.Ack(spoken)
+	playing := <-generation.Sentences()
+
+	interruption := generation.Interrupt()
+	assert.Equal(t, []Sentence{spoken}, interruption.Spoken)
+	assert.Equal(t, []Sentence{playing}, interruption.InFlight)
+	assert.Equal(t, interruption, generation.Interrupt())
+
+	// Everything read from the generator is accounted for
+	delivered := spoken.Text + " " + playing.Text + " " + interruption.Remainder.Text()
+	assert.True(t, strings.HasPrefix(words(strings.Join(text, " ")), words(delivered)), delivered)
+
+	_, open := <-generation.Sentences()
+	assert.False(t, open)
+}
+
+func TestGenerationComplete(t *testing.T) {
+	generator := make(chan string, 1)
+	generator <- "Short answer. Done now."
+	close(generator)
+
+	generation := GenerateSentencesTracked(context.Background(), generator, GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()})
+
+	var received []Sentence
+	for sentence := range generation.Sentences() {
+		received = append(received, sentence)
+	}
+	require.NotEmpty(t, received)
+	generation.Ack(received[len(received)-1])
+
+	interruption := generation.Interrupt()
+	assert.Equal(t, received, interruption.Spoken)
+	assert.Empty(t, interruption.InFlight)
+	assert.True(t, interruption.Empty())
+}
+
+func TestGenerationCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	generation := GenerateSentencesTracked(ctx, endlessGenerator(ctx), GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()})
+
+	<-generation.Sentences()
+	cancel()
+
+	for range generation.Sentences() {
+	}
+	generation.Interrupt()
+}
@@ -0,0 +1,138 @@
+package stream2sentence
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+// endlessGenerator sends sentences until ctx is done
+func endlessGenerator(ctx context.Context) <-chan string {
+	generator := make(chan string)
+	go func() {
+		defer close(generator)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case generator <- "This sentence repeats forever. ":
+			}
+		}
+	}()
+	return generator
+}
+
+// === Goroutine Leak Tests ===
+
+func TestNoLeakAsyncEarlyConsumerExit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sentences := GenerateSentencesAsync(ctx, endlessGenerator(ctx), GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+	})
+
+	// Stop reading after a single sentence while more are being produced
+	<-sentences
+	cancel()
+}
+
+func TestNoLeakAsyncCancelMidChunk(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// One chunk holding far more sentences than the result buffer
+	generator := make(chan string, 1)
+	generator <- strings.Repeat("This is one of many sentences. ", 100)
+
+	sentences := GenerateSentencesAsync(ctx, generator, GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+	})
+	<-sentences
+	cancel()
+}
+
+func TestNoLeakGenerateSentencesEarlyConsumerExit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// The producer never closes its channel, so stopping generation is
+	// the only way to release the goroutine
+	generator := make(chan string, 1)
+	generator <- strings.Repeat("This is one of many sentences. ", 100)
+
+	sentences, stop := GenerateSentencesWithCancel(generator, GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+	})
+	assert.Equal(t, "This is one of many sentences.", <-sentences)
+	stop()
+
+	// The channel is closed once generation has stopped
+	for range sentences {
+	}
+}
+
+func TestNoLeakGenerateSentencesDrained(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	sentences := collectSentences(GenerateSentencesFromString("First sentence. Second sentence.", GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+	}))
+	assert.Len(t, sentences, 2)
+}
+
+func TestNoLeakSourceIteratorBreak(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()}
+	for _, err := range SentencesFromSource(ctx, ChannelSource(endlessGenerator(ctx)), config) {
+		assert.NoError(t, err)
+		break
+	}
+}
+
+func TestNoLeakSplitterClose(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	splitter := NewSentenceSplitter(DefaultConfig())
+	for range 100 {
+		splitter.Add("This is one of many sentences. ")
+	}
+
+	// Abandon the channel after a single sentence, the goroutine feeding
+	// it is released by Close
+	<-splitter.Stream()
+	assert.NoError(t, splitter.Close())
+}
+
+func TestSplitterClosed(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+	splitter.Add(strings.Repeat("This is one of many sentences. ", 100))
+
+	results := splitter.Stream()
+	<-results
+	assert.NoError(t, splitter.Close())
+
+	// The channel closes once the feeding goroutine notices Close
+	received := 1
+	for range results {
+		received++
+	}
+	assert.Less(t, received, 100)
+
+	assert.ErrorIs(t, splitter.Err(), ErrClosed)
+	assert.ErrorIs(t, splitter.Feed("More text. Even more text here."), ErrClosed)
+	assert.ErrorIs(t, splitter.Finish(), ErrClosed)
+	assert.NoError(t, splitter.Close())
+
+	_, ok := <-splitter.Stream()
+	assert.False(t, ok)
+	_, ok = <-splitter.Flush()
+	assert.False(t, ok)
+}
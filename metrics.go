@@ -0,0 +1,216 @@
+package stream2sentence
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SentenceStats describes the timing of a single emitted sentence
+type SentenceStats struct {
+	// Text is the sentence as it was emitted
+	Text string
+
+	// FirstCharAt is the wall-clock time the sentence's first character arrived
+	FirstCharAt time.Time
+
+	// EmittedAt is the wall-clock time the sentence was emitted
+	EmittedAt time.Time
+
+	// Lookahead is the number of characters consumed after the sentence's
+	// last character before the splitter committed to the boundary
+	Lookahead int
+}
+
+// HoldTime returns how long the sentence was held in the splitter
+func (s SentenceStats) HoldTime() time.Duration {
+	return s.EmittedAt.Sub(s.FirstCharAt)
+}
+
+// Stats aggregates latency figures over all sentences recorded by Metrics
+type Stats struct {
+	// Sentences is the number of emitted sentences
+	Sentences int
+
+	// TimeToFirstSentence is the time from the first chunk of a stream
+	// arriving to its first sentence being emitted, the median over all
+	// streams. A stream starts with a new splitter, Reset or Interrupt.
+	TimeToFirstSentence time.Duration
+
+	// HoldTimeP50 and HoldTimeP95 are percentiles of SentenceStats.HoldTime
+	HoldTimeP50 time.Duration
+	HoldTimeP95 time.Duration
+
+	// BufferHighWaterMark is the largest number of characters buffered at once
+	BufferHighWaterMark int
+
+	// Dropped is the number of sentences discarded under LagDropOldest
+	Dropped int
+}
+
+// Metrics records per-sentence timing for a splitter. It is safe for
+// concurrent use, so one Metrics may be shared by several splitters. Its
+// memory is bounded: percentiles are exact up to reservoirSize sentences or
+// streams and estimated from a uniform sample of them beyond that.
+type Metrics struct {
+	// OnSentence, if set, is called with the timing of every emitted sentence
+	OnSentence func(SentenceStats)
+
+	mu              sync.Mutex
+	firstSentences  reservoir
+	holdTimes       reservoir
+	bufferHighWater int
+	dropped         int
+}
+
+// arrival records when a buffered non-space character arrived and its
+// position in the overall input
+type arrival struct {
+	at     time.Time
+	offset int
+}
+
+// reservoirSize is the number of durations kept per percentile series
+const reservoirSize = 1024
+
+// reservoir keeps a uniform random sample of at most reservoirSize of the
+// durations added to it
+type reservoir struct {
+	samples []time.Duration
+	count   int
+}
+
+// add records d, replacing a random sample once the reservoir is full
+func (r *reservoir) add(d time.Duration) {
+	r.count++
+	if len(r.samples) < reservoirSize {
+		r.samples = append(r.samples, d)
+		return
+	}
+	if i := rand.IntN(r.count); i < reservoirSize {
+		r.samples[i] = d
+	}
+}
+
+// sorted returns a sorted copy of the samples
+func (r *reservoir) sorted() []time.Duration {
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// Stats returns the aggregate latency figures recorded so far
+func (m *Metrics) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := Stats{
+		Sentences:           m.holdTimes.count,
+		BufferHighWaterMark: m.bufferHighWater,
+		Dropped:             m.dropped,
+	}
+
+	if m.firstSentences.count > 0 {
+		stats.TimeToFirstSentence = percentile(m.firstSentences.sorted(), 0.50)
+	}
+
+	if m.holdTimes.count > 0 {
+		sorted := m.holdTimes.sorted()
+		stats.HoldTimeP50 = percentile(sorted, 0.50)
+		stats.HoldTimeP95 = percentile(sorted, 0.95)
+	}
+
+	return stats
+}
+
+// recordBuffer updates the buffer high-water mark
+func (m *Metrics) recordBuffer(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bufferHighWater = max(m.bufferHighWater, size)
+}
+
+// recordDrop counts a sentence discarded by the lag policy
+func (m *Metrics) recordDrop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dropped++
+}
+
+// recordSentence stores the timing of an emitted sentence. streamStart is
+// the arrival of the first chunk of the stream if the sentence is the
+// stream's first, zero otherwise.
+func (m *Metrics) recordSentence(stats SentenceStats, streamStart time.Time) {
+	m.mu.Lock()
+	if !streamStart.IsZero() {
+		m.firstSentences.add(stats.EmittedAt.Sub(streamStart))
+	}
+	m.holdTimes.add(stats.HoldTime())
+	m.mu.Unlock()
+
+	if m.OnSentence != nil {
+		m.OnSentence(stats)
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// trackArrival records the arrival of a character that was just buffered
+func (s *SentenceSplitter) trackArrival(char rune, arrivedAt time.Time) {
+	s.consumed++
+	if s.consumed == 1 {
+		s.streamStart = arrivedAt
+	}
+	if !unicode.IsSpace(char) {
+		s.arrivals = append(s.arrivals, arrival{at: arrivedAt, offset: s.consumed})
+	}
+	s.metrics.recordBuffer(utf8.RuneCountInString(s.buffer.String()))
+}
+
+// sentenceStats consumes the arrivals belonging to a sentence taken from
+// the front of the buffer and returns its timing, or false if nothing is
+// left to emit
+func (s *SentenceSplitter) sentenceStats(sentence, text string) (SentenceStats, bool) {
+	// Buffer manipulation never drops or reorders non-space characters,
+	// so they map one-to-one onto the tracked arrivals
+	n := 0
+	for _, r := range sentence {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	n = min(n, len(s.arrivals))
+	if n == 0 {
+		return SentenceStats{}, false
+	}
+
+	first, last := s.arrivals[0], s.arrivals[n-1]
+	s.arrivals = slices.Delete(s.arrivals, 0, n)
+
+	if text == "" {
+		return SentenceStats{}, false
+	}
+
+	return SentenceStats{
+		Text:        text,
+		FirstCharAt: first.at,
+		EmittedAt:   time.Now(),
+		Lookahead:   s.consumed - last.offset,
+	}, true
+}
+
+// recordSentence reports the timing of a sentence that was delivered
+func (s *SentenceSplitter) recordSentence(stats SentenceStats) {
+	s.metrics.recordSentence(stats, s.streamStart)
+	s.streamStart = time.Time{}
+}
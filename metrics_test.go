@@ -0,0 +1,130 @@
+package stream2sentence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsPerSentence(t *testing.T) {
+	var recorded []SentenceStats
+
+	config := DefaultConfig()
+	config.Metrics = &Metrics{
+		OnSentence: func(stats SentenceStats) {
+			recorded = append(recorded, stats)
+		},
+	}
+
+	start := time.Now()
+	text := "This is the first sentence. This is the second sentence. And a third one."
+	sentences := collectSentences(GenerateSentences(createCharacterGenerator(text), GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	}))
+
+	require.Len(t, recorded, len(sentences))
+	for i, stats := range recorded {
+		assert.Equal(t, sentences[i], stats.Text)
+		assert.False(t, stats.FirstCharAt.Before(start))
+		assert.False(t, stats.EmittedAt.Before(stats.FirstCharAt))
+		assert.GreaterOrEqual(t, stats.Lookahead, 0)
+	}
+
+	// The first fragment is quick-yielded on its delimiter, the second needs
+	// the context window to fill before its boundary is confirmed
+	assert.Equal(t, 0, recorded[0].Lookahead)
+	assert.Greater(t, recorded[1].Lookahead, 0)
+
+	// The final sentence is flushed once the input ends
+	assert.Equal(t, 0, recorded[len(recorded)-1].Lookahead)
+}
+
+func TestMetricsStats(t *testing.T) {
+	metrics := &Metrics{}
+
+	config := DefaultConfig()
+	config.Metrics = metrics
+
+	assert.Equal(t, Stats{}, metrics.Stats())
+
+	splitter := NewSentenceSplitter(config)
+	for _, chunk := range []string{"Hello there, ", "this is a test. ", "Another sentence follows here. ", "Done."} {
+		splitter.Add(chunk)
+		for range splitter.Stream() {
+		}
+	}
+	for range splitter.Flush() {
+	}
+
+	stats := metrics.Stats()
+	assert.Equal(t, 4, stats.Sentences)
+	assert.Positive(t, stats.TimeToFirstSentence)
+	assert.LessOrEqual(t, stats.HoldTimeP50, stats.HoldTimeP95)
+	assert.GreaterOrEqual(t, stats.BufferHighWaterMark, config.MinimumSentenceLength+config.ContextSize)
+}
+
+func TestMetricsAfterDelivery(t *testing.T) {
+	metrics := &Metrics{}
+
+	config := DefaultConfig()
+	config.Metrics = metrics
+	config.Callbacks.OnSentence = func(Sentence) error {
+		return errors.New("rejected")
+	}
+
+	splitter := NewSentenceSplitter(config)
+	require.Error(t, splitter.Feed("This sentence is rejected by the callback. And this one never comes."))
+	stats := metrics.Stats()
+	assert.Zero(t, stats.Sentences)
+	assert.Zero(t, stats.TimeToFirstSentence)
+}
+
+func TestMetricsTimeToFirstSentencePerStream(t *testing.T) {
+	metrics := &Metrics{}
+	start := time.Now()
+
+	// Two streams sharing the metrics, the second starting much later
+	metrics.recordSentence(SentenceStats{EmittedAt: start.Add(time.Second)}, start)
+	metrics.recordSentence(SentenceStats{EmittedAt: start.Add(2 * time.Second)}, time.Time{})
+	metrics.recordSentence(SentenceStats{EmittedAt: start.Add(time.Hour + 3*time.Second)}, start.Add(time.Hour))
+	assert.Equal(t, time.Second, metrics.Stats().TimeToFirstSentence)
+
+	// Every stream of a splitter counts once
+	config := DefaultConfig()
+	config.Metrics = &Metrics{}
+	splitter := NewSentenceSplitter(config)
+	for range 2 {
+		require.NoError(t, splitter.Feed("First turn starts here. It goes on."))
+		require.NoError(t, splitter.Finish())
+		splitter.Reset()
+	}
+	assert.Equal(t, 2, config.Metrics.firstSentences.count)
+	assert.Equal(t, 4, config.Metrics.Stats().Sentences)
+}
+
+func TestMetricsBounded(t *testing.T) {
+	metrics := &Metrics{}
+	start := time.Now()
+	for i := range 10 * reservoirSize {
+		metrics.recordSentence(SentenceStats{FirstCharAt: start, EmittedAt: start.Add(time.Duration(i) * time.Millisecond)}, time.Time{})
+	}
+
+	assert.Len(t, metrics.holdTimes.samples, reservoirSize)
+	stats := metrics.Stats()
+	assert.Equal(t, 10*reservoirSize, stats.Sentences)
+
+	// The sample is uniform, so the estimates stay close to the true values
+	assert.InDelta(t, 5*reservoirSize, stats.HoldTimeP50.Milliseconds(), reservoirSize)
+	assert.InDelta(t, 9.5*reservoirSize, stats.HoldTimeP95.Milliseconds(), reservoirSize)
+}
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	assert.Equal(t, time.Duration(5), percentile(durations, 0.50))
+	assert.Equal(t, time.Duration(10), percentile(durations, 0.95))
+	assert.Equal(t, time.Duration(7), percentile([]time.Duration{7}, 0.95))
+}
@@ -0,0 +1,273 @@
+package stream2sentence
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrStreamExpired ends a stream of a Multiplexer that received no
+	// input for MultiplexerConfig.IdleTimeout
+	ErrStreamExpired = errors.New("stream2sentence: stream expired")
+
+	// ErrMultiplexerClosed is returned by Multiplexer.Run once it has run
+	ErrMultiplexerClosed = errors.New("stream2sentence: multiplexer closed")
+)
+
+// StreamChunk is a text chunk for one stream of a Multiplexer
+type StreamChunk struct {
+	StreamID string
+	Text     string
+
+	// End flushes the remaining text of the stream after Text and closes
+	// it. A later chunk with the same ID opens a new stream.
+	End bool
+}
+
+// StreamSentence is a sentence of one stream of a Multiplexer. The last
+// event of every stream has Done set and no text.
+type StreamSentence struct {
+	StreamID string
+	Sentence
+
+	// Done marks the end of the stream
+	Done bool
+
+	// Err tells why the stream ended if it was not closed by an End chunk:
+	// ErrStreamExpired or the error of a failing callback
+	Err error
+}
+
+// MultiplexerConfig holds configuration for a Multiplexer
+type MultiplexerConfig struct {
+	// Splitter is the configuration of every stream's splitter. Its
+	// Output.Buffer sets the capacity of the sentence channel. The lag policy
+	// is ignored and sends always block, as dropping or merging events would
+	// lose the ends of streams and mix their sentences.
+	Splitter SentenceSplitterConfig
+
+	// IdleTimeout closes streams that received no input for this long.
+	// Zero keeps streams open until they end.
+	IdleTimeout time.Duration
+
+	// ExpiryPolicy decides what happens to the buffered text of an expired
+	// stream, DiscardPartial by default
+	ExpiryPolicy UpstreamErrorPolicy
+
+	// MaxBufferedBytes caps the text a stream may buffer without a sentence
+	// boundary. Once a chunk leaves more, the buffer is flushed as if the
+	// stream had ended, and the stream goes on. The last sentence of the cut
+	// is a forced break: it is marked as a Fragment and reported to
+	// OnForcedBreak of the Observer. Zero means no limit.
+	MaxBufferedBytes int
+}
+
+// Multiplexer splits many concurrent streams, e.g. the conversations of a
+// server, into sentences. Chunks of all streams arrive on one input channel
+// and sentences leave on one output channel, each tagged with its stream
+// ID. A single goroutine drives one SentenceSplitter per stream, so a
+// consumer that stops reading holds up all streams.
+type Multiplexer struct {
+	config    MultiplexerConfig
+	sentences chan StreamSentence
+	started   atomic.Bool
+	open      atomic.Int64
+
+	// Open streams by ID, and in order of their last input for expiry
+	streams map[string]*list.Element
+	idle    *list.List
+
+	// now tells the time of input and expiry
+	now func() time.Time
+}
+
+// muxStream is an open stream of a Multiplexer
+type muxStream struct {
+	id         string
+	splitter   *SentenceSplitter
+	lastActive time.Time
+}
+
+// NewMultiplexer creates a Multiplexer with the given configuration
+func NewMultiplexer(config MultiplexerConfig) *Multiplexer {
+	return &Multiplexer{
+		config:    config,
+		sentences: make(chan StreamSentence, config.Splitter.Output.buffer()),
+		streams:   make(map[string]*list.Element),
+		idle:      list.New(),
+		now:       time.Now,
+	}
+}
+
+// Sentences returns the channel of sentences of all streams. It is closed
+// when Run returns.
+func (m *Multiplexer) Sentences() <-chan StreamSentence {
+	return m.sentences
+}
+
+// Streams returns the number of open streams. It may be called from any
+// goroutine.
+func (m *Multiplexer) Streams() int {
+	return int(m.open.Load())
+}
+
+// Run reads chunks from input until it is closed, then flushes and closes
+// all open streams and returns nil. Cancelling ctx stops it right away with
+// ctx.Err(). Run may only be called once; later calls return
+// ErrMultiplexerClosed.
+func (m *Multiplexer) Run(ctx context.Context, input <-chan StreamChunk) error {
+	if !m.started.CompareAndSwap(false, true) {
+		return ErrMultiplexerClosed
+	}
+	defer close(m.sentences)
+
+	// The timer fires when the least recently active stream expires
+	expiry := time.NewTimer(time.Hour)
+	defer expiry.Stop()
+	m.scheduleExpiry(expiry)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-expiry.C:
+			if err := m.expire(ctx, m.now()); err != nil {
+				return err
+			}
+		case chunk, ok := <-input:
+			if !ok {
+				return m.closeAll(ctx)
+			}
+			if err := m.handle(ctx, chunk); err != nil {
+				return err
+			}
+		}
+		m.scheduleExpiry(expiry)
+	}
+}
+
+// handle feeds a chunk to its stream, opening the stream if needed
+func (m *Multiplexer) handle(ctx context.Context, chunk StreamChunk) error {
+	element, ok := m.streams[chunk.StreamID]
+	if !ok {
+		element = m.idle.PushBack(&muxStream{
+			id:       chunk.StreamID,
+			splitter: NewSentenceSplitter(m.config.Splitter),
+		})
+		m.streams[chunk.StreamID] = element
+		m.open.Add(1)
+	}
+
+	stream := element.Value.(*muxStream)
+	stream.lastActive = m.now()
+	m.idle.MoveToBack(element)
+
+	emit := m.emitter(ctx, stream.id)
+	if chunk.Text != "" {
+		stream.splitter.Add(chunk.Text)
+		if err := stream.splitter.process(emit); err != nil {
+			return m.fail(ctx, stream, err)
+		}
+	}
+
+	if chunk.End {
+		return m.finish(ctx, stream, nil)
+	}
+
+	// A runaway stream without sentence boundaries is cut at the cap, which
+	// is a forced break
+	if limit := m.config.MaxBufferedBytes; limit > 0 && stream.splitter.buffer.Len() > limit {
+		if err := stream.splitter.cut(emit); err != nil {
+			return m.fail(ctx, stream, err)
+		}
+	}
+	return nil
+}
+
+// expire closes the streams that have been idle for IdleTimeout at now
+func (m *Multiplexer) expire(ctx context.Context, now time.Time) error {
+	for element := m.idle.Front(); element != nil; element = m.idle.Front() {
+		stream := element.Value.(*muxStream)
+		if now.Sub(stream.lastActive) < m.config.IdleTimeout {
+			break
+		}
+
+		if m.config.ExpiryPolicy == DiscardPartial {
+			stream.splitter.Reset()
+		}
+		if err := m.finish(ctx, stream, ErrStreamExpired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeAll flushes and closes every open stream, oldest first
+func (m *Multiplexer) closeAll(ctx context.Context) error {
+	for element := m.idle.Front(); element != nil; element = m.idle.Front() {
+		if err := m.finish(ctx, element.Value.(*muxStream), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish flushes a stream, removes it and reports its end with reason
+func (m *Multiplexer) finish(ctx context.Context, stream *muxStream, reason error) error {
+	if err := stream.splitter.flush(m.emitter(ctx, stream.id)); err != nil {
+		return m.fail(ctx, stream, err)
+	}
+	return m.remove(ctx, stream, reason)
+}
+
+// fail ends a stream whose splitter failed. Cancellation stops Run, while a
+// failing callback only ends its own stream.
+func (m *Multiplexer) fail(ctx context.Context, stream *muxStream, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	return m.remove(ctx, stream, err)
+}
+
+// remove drops a stream and sends its final event
+func (m *Multiplexer) remove(ctx context.Context, stream *muxStream, reason error) error {
+	m.idle.Remove(m.streams[stream.id])
+	delete(m.streams, stream.id)
+	m.open.Add(-1)
+	stream.splitter.Close()
+
+	return m.send(ctx, StreamSentence{StreamID: stream.id, Done: true, Err: reason})
+}
+
+// emitter returns an emit function tagging sentences with the stream ID
+func (m *Multiplexer) emitter(ctx context.Context, id string) func(Sentence) error {
+	return func(sentence Sentence) error {
+		return m.send(ctx, StreamSentence{StreamID: id, Sentence: sentence})
+	}
+}
+
+// send delivers an event unless ctx is cancelled first
+func (m *Multiplexer) send(ctx context.Context, event StreamSentence) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case m.sentences <- event:
+		return nil
+	}
+}
+
+// scheduleExpiry sets the timer to the expiry of the least recently active
+// stream, or stops it if there is none
+func (m *Multiplexer) scheduleExpiry(timer *time.Timer) {
+	front := m.idle.Front()
+	if m.config.IdleTimeout <= 0 || front == nil {
+		timer.Stop()
+		return
+	}
+
+	lastActive := front.Value.(*muxStream).lastActive
+	timer.Reset(lastActive.Add(m.config.IdleTimeout).Sub(m.now()))
+}
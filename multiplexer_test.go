@@ -0,0 +1,256 @@
+package stream2sentence
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// streamEvents collects the events of a Multiplexer by stream ID
+type streamEvents map[string][]StreamSentence
+
+// texts returns the sentence texts of a stream
+func (e streamEvents) texts(id string) []string {
+	var texts []string
+	for _, event := range e[id] {
+		if !event.Done {
+			texts = append(texts, event.Text)
+		}
+	}
+	return texts
+}
+
+// last returns the final event of a stream
+func (e streamEvents) last(id string) StreamSentence {
+	events := e[id]
+	if len(events) == 0 {
+		return StreamSentence{}
+	}
+	return events[len(events)-1]
+}
+
+// runMultiplexer runs mux on input and collects its events until the
+// sentence channel is closed
+func runMultiplexer(ctx context.Context, mux *Multiplexer, input <-chan StreamChunk) (streamEvents, error) {
+	errc := make(chan error, 1)
+	go func() { errc <- mux.Run(ctx, input) }()
+
+	events := make(streamEvents)
+	for event := range mux.Sentences() {
+		events[event.StreamID] = append(events[event.StreamID], event)
+	}
+	return events, <-errc
+}
+
+// === Multiplexer Tests ===
+
+func TestMultiplexerInterleaved(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	input := make(chan StreamChunk, 20)
+	a := splitRunes("Hello from the first stream. It has two sentences.", 6)
+	b := splitRunes("The second stream talks at the same time. Then it stops.", 9)
+	for i := range max(len(a), len(b)) {
+		if i < len(a) {
+			input <- StreamChunk{StreamID: "a", Text: a[i]}
+		}
+		if i < len(b) {
+			input <- StreamChunk{StreamID: "b", Text: b[i]}
+		}
+	}
+	input <- StreamChunk{StreamID: "a", End: true}
+	input <- StreamChunk{StreamID: "b", End: true}
+	close(input)
+
+	mux := NewMultiplexer(MultiplexerConfig{Splitter: DefaultConfig()})
+	events, err := runMultiplexer(context.Background(), mux, input)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Hello from the first stream.", "It has two sentences."}, events.texts("a"))
+	assert.Equal(t, []string{"The second stream talks at the same time.", "Then it stops."}, events.texts("b"))
+	assert.Equal(t, StreamSentence{StreamID: "a", Done: true}, events.last("a"))
+	assert.Equal(t, StreamSentence{StreamID: "b", Done: true}, events.last("b"))
+	assert.Equal(t, 1, events["b"][1].Index, "indices count per stream")
+	assert.Zero(t, mux.Streams())
+}
+
+func TestMultiplexerInputClosed(t *testing.T) {
+	input := make(chan StreamChunk, 2)
+	input <- StreamChunk{StreamID: "a", Text: "An unfinished thought"}
+	close(input)
+
+	mux := NewMultiplexer(MultiplexerConfig{Splitter: DefaultConfig()})
+	events, err := runMultiplexer(context.Background(), mux, input)
+	require.NoError(t, err)
+
+	// Open streams are flushed and closed
+	assert.Equal(t, []string{"An unfinished thought"}, events.texts("a"))
+	assert.Equal(t, StreamSentence{StreamID: "a", Done: true}, events.last("a"))
+}
+
+func TestMultiplexerReopen(t *testing.T) {
+	input := make(chan StreamChunk, 4)
+	input <- StreamChunk{StreamID: "a", Text: "First turn.", End: true}
+	input <- StreamChunk{StreamID: "a", Text: "Second turn.", End: true}
+	close(input)
+
+	events, err := runMultiplexer(context.Background(), NewMultiplexer(MultiplexerConfig{Splitter: DefaultConfig()}), input)
+	require.NoError(t, err)
+
+	require.Len(t, events["a"], 4)
+	assert.Equal(t, "First turn.", events["a"][0].Text)
+	assert.True(t, events["a"][1].Done)
+	assert.Equal(t, "Second turn.", events["a"][2].Text)
+	assert.Zero(t, events["a"][2].Index)
+}
+
+func TestMultiplexerIdleExpiry(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	for _, policy := range []UpstreamErrorPolicy{DiscardPartial, FlushPartial} {
+		input := make(chan StreamChunk)
+		mux := NewMultiplexer(MultiplexerConfig{
+			Splitter:     DefaultConfig(),
+			IdleTimeout:  30 * time.Millisecond,
+			ExpiryPolicy: policy,
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go mux.Run(ctx, input)
+
+		input <- StreamChunk{StreamID: "a", Text: "The user walked away mid"}
+		assert.Equal(t, 1, mux.Streams())
+
+		if policy == FlushPartial {
+			event := <-mux.Sentences()
+			assert.Equal(t, "The user walked away mid", event.Text)
+		}
+		event := <-mux.Sentences()
+		assert.Equal(t, StreamSentence{StreamID: "a", Done: true, Err: ErrStreamExpired}, event)
+		assert.Zero(t, mux.Streams())
+
+		cancel()
+		for range mux.Sentences() {
+		}
+	}
+}
+
+func TestMultiplexerActivityDefersExpiry(t *testing.T) {
+	mux := NewMultiplexer(MultiplexerConfig{
+		Splitter:    DefaultConfig(),
+		IdleTimeout: 50 * time.Millisecond,
+	})
+	start := time.Now()
+	now := start
+	mux.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Stream b goes quiet and expires while a keeps talking
+	require.NoError(t, mux.handle(ctx, StreamChunk{StreamID: "b", Text: "Quiet"}))
+	for range 20 {
+		require.NoError(t, mux.handle(ctx, StreamChunk{StreamID: "a", Text: "la "}))
+		now = now.Add(5 * time.Millisecond)
+		require.NoError(t, mux.expire(ctx, now))
+
+		open := 2
+		if now.Sub(start) >= 50*time.Millisecond {
+			open = 1
+		}
+		assert.Equal(t, open, mux.Streams(), "at %v", now.Sub(start))
+	}
+	require.NoError(t, mux.handle(ctx, StreamChunk{StreamID: "a", End: true}))
+	close(mux.sentences)
+
+	var ended []StreamSentence
+	for event := range mux.Sentences() {
+		if event.Done {
+			ended = append(ended, event)
+		}
+	}
+	assert.Equal(t, []StreamSentence{
+		{StreamID: "b", Done: true, Err: ErrStreamExpired},
+		{StreamID: "a", Done: true},
+	}, ended)
+}
+
+func TestMultiplexerMaxBuffered(t *testing.T) {
+	observer := &recordingObserver{}
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	config.Observer = observer
+
+	input := make(chan StreamChunk, 10)
+	for _, chunk := range []string{"no punctuation ", "at all in this ", "very long ", "stream"} {
+		input <- StreamChunk{StreamID: "a", Text: chunk}
+	}
+	close(input)
+
+	mux := NewMultiplexer(MultiplexerConfig{Splitter: config, MaxBufferedBytes: 24})
+	events, err := runMultiplexer(context.Background(), mux, input)
+	require.NoError(t, err)
+
+	texts := events.texts("a")
+	assert.Equal(t, []string{"no punctuation at all in this", "very long stream"}, texts)
+
+	// The cut is a forced break, the end of the stream is not
+	require.NotEmpty(t, events["a"])
+	assert.True(t, events["a"][0].Fragment)
+	assert.Equal(t, []string{"no punctuation at all in this"}, observer.forcedBreaks)
+}
+
+func TestMultiplexerCallbackError(t *testing.T) {
+	errRejected := errors.New("rejected")
+	config := DefaultConfig()
+	config.Callbacks.OnSentence = func(sentence Sentence) error {
+		if strings.Contains(sentence.Text, "forbidden") {
+			return errRejected
+		}
+		return nil
+	}
+
+	input := make(chan StreamChunk, 4)
+	input <- StreamChunk{StreamID: "bad", Text: "This is forbidden text. More follows."}
+	input <- StreamChunk{StreamID: "good", Text: "This one is fine. Really.", End: true}
+	input <- StreamChunk{StreamID: "bad", Text: "A new stream starts.", End: true}
+	close(input)
+
+	events, err := runMultiplexer(context.Background(), NewMultiplexer(MultiplexerConfig{Splitter: config}), input)
+	require.NoError(t, err)
+
+	// Only the failing stream ends
+	require.NotEmpty(t, events["bad"])
+	assert.Equal(t, StreamSentence{StreamID: "bad", Done: true, Err: errRejected}, events["bad"][0])
+	assert.Equal(t, []string{"A new stream starts."}, events.texts("bad"))
+	assert.Equal(t, []string{"This one is fine.", "Really."}, events.texts("good"))
+}
+
+func TestMultiplexerCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	config := DefaultConfig()
+	config.Output.Buffer = 2
+
+	input := make(chan StreamChunk)
+	mux := NewMultiplexer(MultiplexerConfig{Splitter: config})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- mux.Run(ctx, input) }()
+
+	// Nobody reads, so the first stream fills the channel with its sentence
+	// and end, and the multiplexer blocks sending the second stream's
+	input <- StreamChunk{StreamID: "a", Text: "First.", End: true}
+	input <- StreamChunk{StreamID: "b", Text: "Second.", End: true}
+	cancel()
+
+	assert.ErrorIs(t, <-errc, context.Canceled)
+	for range mux.Sentences() {
+	}
+	assert.ErrorIs(t, mux.Run(context.Background(), input), ErrMultiplexerClosed)
+}
@@ -0,0 +1,214 @@
+// Package ndjson reads newline-delimited JSON token streams, such as those of
+// Ollama and llama.cpp, as a stream2sentence chunk source
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// ErrTruncated is returned when a stream ends in the middle of an object or,
+// if it has a done flag, before the flag was set
+var ErrTruncated = errors.New("ndjson: stream ended before done")
+
+// ModelError is an error reported by the model server inside the stream
+type ModelError struct {
+	Message string
+}
+
+func (e *ModelError) Error() string {
+	return "ndjson: model error: " + e.Message
+}
+
+// Config locates the fields of a stream's JSON objects. Paths are
+// dot-separated object keys; numeric segments index arrays, e.g.
+// "choices.0.text".
+type Config struct {
+	// TextPath is the path of the text chunk of each object
+	TextPath string
+
+	// DonePath is the path of the boolean flag marking the last object. If
+	// empty, the stream ends at the end of the input.
+	DonePath string
+
+	// ErrorPath is the path of an error reported by the server. Its value
+	// may be a string or an object with a "message" field.
+	ErrorPath string
+}
+
+// OllamaGenerateConfig returns the configuration for Ollama's /api/generate
+func OllamaGenerateConfig() Config {
+	return Config{TextPath: "response", DonePath: "done", ErrorPath: "error"}
+}
+
+// OllamaChatConfig returns the configuration for Ollama's /api/chat
+func OllamaChatConfig() Config {
+	return Config{TextPath: "message.content", DonePath: "done", ErrorPath: "error"}
+}
+
+// LlamaCppConfig returns the configuration for the llama.cpp server's
+// /completion endpoint
+func LlamaCppConfig() Config {
+	return Config{TextPath: "content", DonePath: "stop", ErrorPath: "error"}
+}
+
+// Source reads the text chunks of a newline-delimited JSON stream. Blank
+// lines are skipped, and a leading "data:" is removed from each line, so
+// servers that frame their objects as Server-Sent Events are read as well.
+// Source implements stream2sentence.Source.
+type Source struct {
+	r      *bufio.Reader
+	config Config
+	line   int
+	done   bool
+
+	chunks stream2sentence.SourceChannel
+}
+
+var _ stream2sentence.Source = (*Source)(nil)
+
+// NewSource returns a Source reading a stream from r, e.g. the body of a
+// streaming HTTP response
+func NewSource(r io.Reader, config Config) *Source {
+	return &Source{r: bufio.NewReader(r), config: config}
+}
+
+// Next returns the next non-empty text chunk. It returns io.EOF at the end of
+// the stream, ErrTruncated if the input ends before the done flag was set and
+// a *ModelError for errors reported in the stream. Reads from the underlying
+// reader are not interrupted by ctx; cancel the HTTP request to abort a
+// blocked read.
+func (s *Source) Next(ctx context.Context) (string, error) {
+	for {
+		if s.done {
+			return "", io.EOF
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		line, err := s.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			if errors.Is(err, io.EOF) && s.config.DonePath != "" {
+				return "", ErrTruncated
+			}
+			return "", err
+		}
+		s.line++
+
+		// A final line without newline may have been cut off
+		unterminated := err != nil
+
+		line = bytes.TrimSpace(line)
+		line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if len(line) == 0 {
+			continue
+		}
+
+		text, err := s.decode(line)
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if unterminated && (errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF)) {
+				return "", ErrTruncated
+			}
+			return "", err
+		}
+		if text != "" {
+			return text, nil
+		}
+	}
+}
+
+// decode extracts the text of a line and records whether it is the last
+func (s *Source) decode(line []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	var object any
+	if err := decoder.Decode(&object); err != nil {
+		return "", fmt.Errorf("ndjson: invalid JSON on line %d: %w", s.line, err)
+	}
+
+	if s.config.ErrorPath != "" {
+		if value, ok := lookup(object, s.config.ErrorPath); ok && value != nil {
+			return "", &ModelError{Message: errorMessage(value)}
+		}
+	}
+
+	var text string
+	if value, ok := lookup(object, s.config.TextPath); ok && value != nil {
+		var isString bool
+		if text, isString = value.(string); !isString {
+			return "", fmt.Errorf("ndjson: %s on line %d is not a string", s.config.TextPath, s.line)
+		}
+	}
+
+	if s.config.DonePath != "" {
+		if done, _ := lookup(object, s.config.DonePath); done == true {
+			s.done = true
+		}
+	}
+
+	return text, nil
+}
+
+// Chunks returns a channel of the text chunks of the stream, for use with
+// stream2sentence.GenerateSentences. The channel is closed at the end of the
+// stream, on error or when ctx is done; Err then reports why.
+func (s *Source) Chunks(ctx context.Context) <-chan string {
+	return s.chunks.Chunks(ctx, s)
+}
+
+// Err returns the error that ended the channel returned by Chunks, or nil if
+// the stream completed normally
+func (s *Source) Err() error {
+	return s.chunks.Err()
+}
+
+// lookup returns the value at a dot-separated path in a decoded JSON value
+func lookup(value any, path string) (any, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// errorMessage returns the message of an error value
+func errorMessage(value any) string {
+	if message, ok := value.(string); ok {
+		return message
+	}
+
+	if message, ok := lookup(value, "message"); ok {
+		if message, ok := message.(string); ok {
+			return message
+		}
+	}
+
+	data, _ := json.Marshal(value)
+	return string(data)
+}
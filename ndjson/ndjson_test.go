@@ -0,0 +1,159 @@
+package ndjson
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// openFixture opens a recorded stream from testdata
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// readText reads all text chunks until the stream ends
+func readText(source *Source) (string, error) {
+	var text strings.Builder
+	for {
+		chunk, err := source.Next(context.Background())
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return text.String(), err
+		}
+		text.WriteString(chunk)
+	}
+}
+
+func TestSourceFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		config  Config
+		text    string
+	}{
+		{"ollama_generate.ndjson", OllamaGenerateConfig(), "The sky is blue because of Rayleigh scattering. Sunsets look red."},
+		{"ollama_chat.ndjson", OllamaChatConfig(), "Hello! How can I help you today?"},
+		{"llamacpp.ndjson", LlamaCppConfig(), "Once upon a time, there was a fox."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			text, err := readText(NewSource(openFixture(t, tt.fixture), tt.config))
+			require.NoError(t, err)
+			assert.Equal(t, tt.text, text)
+		})
+	}
+}
+
+func TestSourceModelErrors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		config  Config
+		text    string
+		message string
+	}{
+		{"ollama_error.ndjson", OllamaGenerateConfig(), "First sentence. Second", "an error was encountered while running the model: unexpected EOF"},
+		{"llamacpp_error.ndjson", LlamaCppConfig(), "Partial", "context shift is disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			text, err := readText(NewSource(openFixture(t, tt.fixture), tt.config))
+			assert.Equal(t, tt.text, text)
+
+			var modelErr *ModelError
+			require.ErrorAs(t, err, &modelErr)
+			assert.Equal(t, tt.message, modelErr.Message)
+		})
+	}
+}
+
+func TestSourceTruncated(t *testing.T) {
+	text, err := readText(NewSource(openFixture(t, "truncated.ndjson"), OllamaGenerateConfig()))
+	assert.Equal(t, "Cut off", text)
+	assert.ErrorIs(t, err, ErrTruncated)
+
+	// Without a done flag only a cut-off object counts as truncation
+	config := Config{TextPath: "response"}
+	text, err = readText(NewSource(strings.NewReader(`{"response": "Fine"}`), config))
+	require.NoError(t, err)
+	assert.Equal(t, "Fine", text)
+
+	_, err = readText(NewSource(strings.NewReader("{\"response\": \"Fine\"}\n"), OllamaGenerateConfig()))
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestSourcePaths(t *testing.T) {
+	stream := `{"choices": [{"text": "Array "}], "finished": false}
+{"choices": [{"text": "paths work."}], "finished": true}
+{"choices": [{"text": "Never read."}]}
+`
+	config := Config{TextPath: "choices.0.text", DonePath: "finished"}
+
+	text, err := readText(NewSource(strings.NewReader(stream), config))
+	require.NoError(t, err)
+	assert.Equal(t, "Array paths work.", text)
+}
+
+func TestSourceInvalid(t *testing.T) {
+	_, err := readText(NewSource(strings.NewReader("{\"response\": 1}\n"), OllamaGenerateConfig()))
+	assert.ErrorContains(t, err, "response on line 1 is not a string")
+
+	_, err = readText(NewSource(strings.NewReader("\n\nnot json\n"), OllamaGenerateConfig()))
+	assert.ErrorContains(t, err, "line 3")
+}
+
+func TestSourceSentences(t *testing.T) {
+	source := NewSource(openFixture(t, "ollama_error.ndjson"), OllamaGenerateConfig())
+	config := stream2sentence.GenerateSentencesConfig{SentenceSplitterConfig: stream2sentence.DefaultConfig()}
+
+	var sentences []string
+	var err error
+	for sentence, e := range stream2sentence.SentencesFromSource(context.Background(), source, config) {
+		if e != nil {
+			err = e
+			break
+		}
+		sentences = append(sentences, sentence.Text)
+	}
+
+	assert.Equal(t, []string{"First sentence."}, sentences)
+	assert.ErrorIs(t, err, stream2sentence.ErrUpstream)
+
+	var modelErr *ModelError
+	assert.ErrorAs(t, err, &modelErr)
+}
+
+func TestSourceChunks(t *testing.T) {
+	source := NewSource(openFixture(t, "ollama_generate.ndjson"), OllamaGenerateConfig())
+
+	var sentences []string
+	for sentence := range stream2sentence.GenerateSentences(source.Chunks(context.Background()), stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: stream2sentence.DefaultConfig(),
+	}) {
+		sentences = append(sentences, sentence)
+	}
+
+	require.NoError(t, source.Err())
+	assert.Equal(t, "The sky is blue because of Rayleigh scattering. Sunsets look red.", strings.Join(sentences, " "))
+
+	source = NewSource(openFixture(t, "truncated.ndjson"), OllamaGenerateConfig())
+	for range source.Chunks(context.Background()) {
+	}
+	assert.ErrorIs(t, source.Err(), ErrTruncated)
+}
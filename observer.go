@@ -0,0 +1,87 @@
+package stream2sentence
+
+// Observer receives notifications about a splitter's activity, e.g. to feed
+// tracing or metrics systems. OnChunk runs on the goroutine calling Add, all
+// other callbacks synchronously on the goroutine processing the stream, so
+// they should return quickly. An Observer shared between splitters, or used
+// with concurrent producers, must be safe for concurrent use.
+type Observer interface {
+	// OnChunk is called for every chunk passed to Add
+	OnChunk(chunk string)
+
+	// OnSentence is called for every sentence once it is delivered, so
+	// sentences rejected by a callback or cut off by cancellation or
+	// Interrupt are not reported
+	OnSentence(sentence string)
+
+	// OnFlush is called when the splitter is flushed, with the text that
+	// was still buffered
+	OnFlush(buffered string)
+
+	// OnForcedBreak is called when a sentence without a confirmed sentence
+	// boundary, such as a quick-yielded first fragment, is delivered
+	OnForcedBreak(sentence string)
+
+	// OnCleanupApplied is called when text cleanup removed more than the
+	// surrounding whitespace of a sentence
+	OnCleanupApplied(before, after string, flags CleanupFlags)
+
+	// OnDrop is called when an emitted sentence is discarded unread because
+	// the consumer lags behind under LagDropOldest
+	OnDrop(sentence string)
+}
+
+// NopObserver is an Observer that ignores all notifications. Embed it to
+// implement only the callbacks of interest.
+type NopObserver struct{}
+
+func (NopObserver) OnChunk(string)                                {}
+func (NopObserver) OnSentence(string)                             {}
+func (NopObserver) OnFlush(string)                                {}
+func (NopObserver) OnForcedBreak(string)                          {}
+func (NopObserver) OnCleanupApplied(string, string, CleanupFlags) {}
+func (NopObserver) OnDrop(string)                                 {}
+
+// multiObserver fans notifications out to several observers
+type multiObserver []Observer
+
+// MultiObserver returns an Observer that notifies all given observers in order
+func MultiObserver(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+func (m multiObserver) OnChunk(chunk string) {
+	for _, o := range m {
+		o.OnChunk(chunk)
+	}
+}
+
+func (m multiObserver) OnSentence(sentence string) {
+	for _, o := range m {
+		o.OnSentence(sentence)
+	}
+}
+
+func (m multiObserver) OnFlush(buffered string) {
+	for _, o := range m {
+		o.OnFlush(buffered)
+	}
+}
+
+func (m multiObserver) OnForcedBreak(sentence string) {
+	for _, o := range m {
+		o.OnForcedBreak(sentence)
+	}
+}
+
+func (m multiObserver) OnCleanupApplied(before, after string, flags CleanupFlags) {
+	for _, o := range m {
+		o.OnCleanupApplied(before, after, flags)
+	}
+}
+
+func (m multiObserver) OnDrop(sentence string) {
+	for _, o := range m {
+		o.OnDrop(sentence)
+	}
+}
@@ -0,0 +1,82 @@
+package stream2sentence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver records the notifications it receives
+type recordingObserver struct {
+	chunks       []string
+	sentences    []string
+	flushes      []string
+	forcedBreaks []string
+	cleanups     [][2]string
+	drops        []string
+}
+
+func (o *recordingObserver) OnChunk(chunk string)       { o.chunks = append(o.chunks, chunk) }
+func (o *recordingObserver) OnSentence(sentence string) { o.sentences = append(o.sentences, sentence) }
+func (o *recordingObserver) OnFlush(buffered string)    { o.flushes = append(o.flushes, buffered) }
+func (o *recordingObserver) OnForcedBreak(sentence string) {
+	o.forcedBreaks = append(o.forcedBreaks, sentence)
+}
+func (o *recordingObserver) OnCleanupApplied(before, after string, _ CleanupFlags) {
+	o.cleanups = append(o.cleanups, [2]string{before, after})
+}
+func (o *recordingObserver) OnDrop(sentence string) { o.drops = append(o.drops, sentence) }
+
+func TestObserverNotifications(t *testing.T) {
+	observer := &recordingObserver{}
+
+	config := DefaultConfig()
+	config.Observer = observer
+
+	chunks := []string{"Hello there, ", "it is a fine day 😀. ", "Another sentence follows here. ", "Bye now."}
+	sentences := collectSentences(GenerateSentencesFromSlice(chunks, GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	}))
+
+	assert.Equal(t, chunks, observer.chunks)
+	assert.Equal(t, sentences, observer.sentences)
+	assert.Equal(t, []string{"Bye now."}, observer.flushes)
+	assert.Equal(t, []string{"Hello there,"}, observer.forcedBreaks)
+	assert.Equal(t, [][2]string{{"it is a fine day 😀.", "it is a fine day ."}}, observer.cleanups)
+}
+
+func TestObserverSkipsRejectedSentences(t *testing.T) {
+	observer := &recordingObserver{}
+	rejected := errors.New("rejected")
+
+	config := DefaultConfig()
+	config.Observer = observer
+	config.Callbacks.OnFragment = func(Sentence) error { return rejected }
+
+	splitter := NewSentenceSplitter(config)
+	assert.ErrorIs(t, splitter.Feed("Hello there, it is a fine day. "), rejected)
+	assert.Empty(t, observer.sentences)
+	assert.Empty(t, observer.forcedBreaks)
+}
+
+func TestMultiObserver(t *testing.T) {
+	first, second := &recordingObserver{}, &recordingObserver{}
+	observer := MultiObserver(first, NopObserver{}, second)
+
+	observer.OnChunk("chunk")
+	observer.OnSentence("sentence")
+	observer.OnFlush("buffered")
+	observer.OnForcedBreak("forced")
+	observer.OnCleanupApplied("before", "after", CleanupAll)
+	observer.OnDrop("dropped")
+
+	for _, o := range []*recordingObserver{first, second} {
+		assert.Equal(t, []string{"chunk"}, o.chunks)
+		assert.Equal(t, []string{"sentence"}, o.sentences)
+		assert.Equal(t, []string{"buffered"}, o.flushes)
+		assert.Equal(t, []string{"forced"}, o.forcedBreaks)
+		assert.Equal(t, [][2]string{{"before", "after"}}, o.cleanups)
+		assert.Equal(t, []string{"dropped"}, o.drops)
+	}
+}
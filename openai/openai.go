@@ -0,0 +1,203 @@
+// Package openai reads OpenAI-compatible chat completion streams, the
+// Server-Sent Events returned with "stream": true, as a stream2sentence
+// chunk source
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/sse"
+)
+
+// doneMarker is the data of the event that ends a stream
+const doneMarker = "[DONE]"
+
+// ErrTruncated is returned when a stream ends without the [DONE] marker
+var ErrTruncated = errors.New("openai: stream ended without [DONE]")
+
+// APIError is an error reported inside the stream, either as an error event
+// or as a chunk with an error object
+type APIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+	Param   string `json:"param"`
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return "openai: " + e.Message
+	}
+	return fmt.Sprintf("openai: %s: %s", e.Type, e.Message)
+}
+
+// ToolCallDelta is an incremental piece of a tool call. The first delta of
+// a call carries its ID and function name; later deltas with the same Index
+// append to Arguments.
+type ToolCallDelta struct {
+	Choice    int
+	Index     int
+	ID        string
+	Type      string
+	Name      string
+	Arguments string
+}
+
+// Config holds the options of a Source
+type Config struct {
+	// Choice is the index of the choice whose content is read, 0 by default
+	Choice int
+
+	// OnToolCall, if set, receives every tool call delta of the selected
+	// choice. Tool calls are ignored otherwise.
+	OnToolCall func(ToolCallDelta)
+
+	// OnFinish, if set, is called with the finish reason of the selected
+	// choice, e.g. "stop", "length" or "tool_calls"
+	OnFinish func(reason string)
+}
+
+// chunk is the payload of a chat.completion.chunk event
+type chunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content   *string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *APIError `json:"error"`
+}
+
+// Source reads the content deltas of a chat completion stream. It implements
+// stream2sentence.Source.
+type Source struct {
+	events *sse.Reader
+	config Config
+	done   bool
+
+	chunks stream2sentence.SourceChannel
+}
+
+var _ stream2sentence.Source = (*Source)(nil)
+
+// NewSource returns a Source reading a chat completion stream from r, e.g.
+// the body of a streaming HTTP response
+func NewSource(r io.Reader, config Config) *Source {
+	return &Source{events: sse.NewReader(r), config: config}
+}
+
+// Next returns the next non-empty content delta. It returns io.EOF after the
+// [DONE] marker, ErrTruncated if the stream ends before it and an *APIError
+// for errors reported in the stream. Reads from the underlying reader are not
+// interrupted by ctx; cancel the HTTP request to abort a blocked read.
+func (s *Source) Next(ctx context.Context) (string, error) {
+	for {
+		if s.done {
+			return "", io.EOF
+		}
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		event, err := s.events.Next()
+		if errors.Is(err, io.EOF) {
+			return "", ErrTruncated
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if event.Data == doneMarker {
+			s.done = true
+			return "", io.EOF
+		}
+
+		content, err := s.handle(event)
+		if err != nil {
+			return "", err
+		}
+		if content != "" {
+			return content, nil
+		}
+	}
+}
+
+// handle decodes an event and returns its content for the selected choice
+func (s *Source) handle(event sse.Event) (string, error) {
+	if event.Type == "error" {
+		var payload struct {
+			Error *APIError `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil || payload.Error == nil {
+			return "", &APIError{Message: event.Data}
+		}
+		return "", payload.Error
+	}
+
+	var c chunk
+	if err := json.Unmarshal([]byte(event.Data), &c); err != nil {
+		return "", fmt.Errorf("openai: invalid chunk: %w", err)
+	}
+	if c.Error != nil {
+		return "", c.Error
+	}
+
+	var content string
+	for _, choice := range c.Choices {
+		if choice.Index != s.config.Choice {
+			continue
+		}
+
+		if choice.Delta.Content != nil {
+			content += *choice.Delta.Content
+		}
+
+		if s.config.OnToolCall != nil {
+			for _, call := range choice.Delta.ToolCalls {
+				s.config.OnToolCall(ToolCallDelta{
+					Choice:    choice.Index,
+					Index:     call.Index,
+					ID:        call.ID,
+					Type:      call.Type,
+					Name:      call.Function.Name,
+					Arguments: call.Function.Arguments,
+				})
+			}
+		}
+
+		if choice.FinishReason != nil && s.config.OnFinish != nil {
+			s.config.OnFinish(*choice.FinishReason)
+		}
+	}
+
+	return content, nil
+}
+
+// Chunks returns a channel of the content deltas of the stream, for use with
+// stream2sentence.GenerateSentences. The channel is closed at the end of the
+// stream, on error or when ctx is done; Err then reports why.
+func (s *Source) Chunks(ctx context.Context) <-chan string {
+	return s.chunks.Chunks(ctx, s)
+}
+
+// Err returns the error that ended the channel returned by Chunks, or nil if
+// the stream completed normally
+func (s *Source) Err() error {
+	return s.chunks.Err()
+}
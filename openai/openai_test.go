@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// openFixture opens a recorded stream from testdata
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// readContent reads all content deltas until the stream ends
+func readContent(source *Source) (string, error) {
+	var content strings.Builder
+	for {
+		chunk, err := source.Next(context.Background())
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return content.String(), err
+		}
+		content.WriteString(chunk)
+	}
+}
+
+func TestSourceFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		content string
+	}{
+		{"basic.sse", "Sure! The capital of France is Paris. It has about 2.1 million residents."},
+		{"crlf.sse", "Sure! The capital of France is Paris. It has about 2.1 million residents."},
+		{"multiline.sse", "Split across lines. Done."},
+		{"tool_calls.sse", "Let me check the weather."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			content, err := readContent(NewSource(openFixture(t, tt.fixture), Config{}))
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, content)
+		})
+	}
+}
+
+func TestSourceToolCalls(t *testing.T) {
+	var calls []ToolCallDelta
+	var reasons []string
+	source := NewSource(openFixture(t, "tool_calls.sse"), Config{
+		OnToolCall: func(delta ToolCallDelta) { calls = append(calls, delta) },
+		OnFinish:   func(reason string) { reasons = append(reasons, reason) },
+	})
+
+	_, err := readContent(source)
+	require.NoError(t, err)
+
+	require.Len(t, calls, 4)
+	assert.Equal(t, ToolCallDelta{ID: "call_Qx1", Type: "function", Name: "get_weather"}, calls[0])
+
+	var arguments strings.Builder
+	for _, call := range calls {
+		assert.Equal(t, 0, call.Index)
+		arguments.WriteString(call.Arguments)
+	}
+	assert.Equal(t, `{"location":"Paris"}`, arguments.String())
+	assert.Equal(t, []string{"tool_calls"}, reasons)
+}
+
+func TestSourceErrorEvent(t *testing.T) {
+	content, err := readContent(NewSource(openFixture(t, "error.sse"), Config{}))
+	assert.Equal(t, "This sentence is complete. This one", content)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "server_error", apiErr.Type)
+	assert.Equal(t, "openai: server_error: The server had an error while processing your request.", err.Error())
+}
+
+func TestSourceErrorChunk(t *testing.T) {
+	stream := `data: {"error": {"message": "Rate limit reached", "type": "rate_limit_error", "code": "rate_limit_exceeded"}}` + "\n\n"
+
+	_, err := readContent(NewSource(strings.NewReader(stream), Config{}))
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "rate_limit_exceeded", apiErr.Code)
+}
+
+func TestSourceTruncated(t *testing.T) {
+	content, err := readContent(NewSource(openFixture(t, "truncated.sse"), Config{}))
+	assert.Equal(t, "Cut off mid", content)
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestSourceChoice(t *testing.T) {
+	stream := `data: {"choices": [{"index": 0, "delta": {"content": "zero"}}, {"index": 1, "delta": {"content": "one"}}]}` + "\n\n" +
+		"data: [DONE]\n\n"
+
+	content, err := readContent(NewSource(strings.NewReader(stream), Config{Choice: 1}))
+	require.NoError(t, err)
+	assert.Equal(t, "one", content)
+}
+
+func TestSourceSentences(t *testing.T) {
+	source := NewSource(openFixture(t, "error.sse"), Config{})
+
+	var sentences []string
+	var err error
+	for sentence, e := range stream2sentence.SentencesFromSource(context.Background(), source, stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: stream2sentence.DefaultConfig(),
+	}) {
+		if e != nil {
+			err = e
+			break
+		}
+		sentences = append(sentences, sentence.Text)
+	}
+
+	assert.Equal(t, []string{"This sentence is complete."}, sentences)
+	assert.ErrorIs(t, err, stream2sentence.ErrUpstream)
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+}
+
+func TestSourceChunks(t *testing.T) {
+	source := NewSource(openFixture(t, "basic.sse"), Config{})
+
+	var sentences []string
+	for sentence := range stream2sentence.GenerateSentences(source.Chunks(context.Background()), stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: stream2sentence.DefaultConfig(),
+	}) {
+		sentences = append(sentences, sentence)
+	}
+
+	require.NoError(t, source.Err())
+	assert.Equal(t, "Sure! The capital of France is Paris. It has about 2.1 million residents.", strings.Join(sentences, " "))
+
+	source = NewSource(openFixture(t, "truncated.sse"), Config{})
+	for range source.Chunks(context.Background()) {
+	}
+	assert.ErrorIs(t, source.Err(), ErrTruncated)
+}
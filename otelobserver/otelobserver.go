@@ -0,0 +1,121 @@
+// Package otelobserver records stream2sentence splitter activity as
+// OpenTelemetry span events and metrics. It lives in its own module so the
+// core package does not depend on the OpenTelemetry SDK.
+package otelobserver
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// Instrument and event names
+const (
+	ChunksMetric          = "stream2sentence.chunks"
+	SentencesMetric       = "stream2sentence.sentences"
+	SentenceLengthMetric  = "stream2sentence.sentence.length"
+	FlushesMetric         = "stream2sentence.flushes"
+	ForcedBreaksMetric    = "stream2sentence.forced_breaks"
+	CleanupsAppliedMetric = "stream2sentence.cleanups_applied"
+	DropsMetric           = "stream2sentence.drops"
+
+	SentenceEvent    = "stream2sentence.sentence"
+	FlushEvent       = "stream2sentence.flush"
+	ForcedBreakEvent = "stream2sentence.forced_break"
+
+	LengthKey = attribute.Key("stream2sentence.length")
+)
+
+// Observer adds span events to the span carried by its context and records
+// counters and a sentence length histogram with its meter
+type Observer struct {
+	ctx   context.Context
+	span  trace.Span
+	attrs metric.MeasurementOption
+
+	chunks          metric.Int64Counter
+	sentences       metric.Int64Counter
+	sentenceLength  metric.Int64Histogram
+	flushes         metric.Int64Counter
+	forcedBreaks    metric.Int64Counter
+	cleanupsApplied metric.Int64Counter
+	drops           metric.Int64Counter
+}
+
+var _ stream2sentence.Observer = (*Observer)(nil)
+
+// New returns an Observer reporting to the span in ctx, if any, and to meter.
+// The given attributes are attached to every measurement.
+func New(ctx context.Context, meter metric.Meter, attrs ...attribute.KeyValue) (*Observer, error) {
+	o := &Observer{
+		ctx:   ctx,
+		span:  trace.SpanFromContext(ctx),
+		attrs: metric.WithAttributes(attrs...),
+	}
+
+	var err error
+	if o.chunks, err = meter.Int64Counter(ChunksMetric,
+		metric.WithDescription("Text chunks received by the splitter")); err != nil {
+		return nil, err
+	}
+	if o.sentences, err = meter.Int64Counter(SentencesMetric,
+		metric.WithDescription("Sentences emitted by the splitter")); err != nil {
+		return nil, err
+	}
+	if o.sentenceLength, err = meter.Int64Histogram(SentenceLengthMetric,
+		metric.WithDescription("Length of emitted sentences"), metric.WithUnit("{char}")); err != nil {
+		return nil, err
+	}
+	if o.flushes, err = meter.Int64Counter(FlushesMetric,
+		metric.WithDescription("Splitter flushes")); err != nil {
+		return nil, err
+	}
+	if o.forcedBreaks, err = meter.Int64Counter(ForcedBreaksMetric,
+		metric.WithDescription("Sentences emitted without a confirmed boundary")); err != nil {
+		return nil, err
+	}
+	if o.cleanupsApplied, err = meter.Int64Counter(CleanupsAppliedMetric,
+		metric.WithDescription("Sentences altered by text cleanup")); err != nil {
+		return nil, err
+	}
+	if o.drops, err = meter.Int64Counter(DropsMetric,
+		metric.WithDescription("Sentences discarded because the consumer lagged behind")); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (o *Observer) OnChunk(string) {
+	o.chunks.Add(o.ctx, 1, o.attrs)
+}
+
+func (o *Observer) OnSentence(sentence string) {
+	length := int64(utf8.RuneCountInString(sentence))
+	o.sentences.Add(o.ctx, 1, o.attrs)
+	o.sentenceLength.Record(o.ctx, length, o.attrs)
+	o.span.AddEvent(SentenceEvent, trace.WithAttributes(LengthKey.Int64(length)))
+}
+
+func (o *Observer) OnFlush(buffered string) {
+	o.flushes.Add(o.ctx, 1, o.attrs)
+	o.span.AddEvent(FlushEvent, trace.WithAttributes(LengthKey.Int(utf8.RuneCountInString(buffered))))
+}
+
+func (o *Observer) OnForcedBreak(sentence string) {
+	o.forcedBreaks.Add(o.ctx, 1, o.attrs)
+	o.span.AddEvent(ForcedBreakEvent, trace.WithAttributes(LengthKey.Int(utf8.RuneCountInString(sentence))))
+}
+
+func (o *Observer) OnCleanupApplied(string, string, stream2sentence.CleanupFlags) {
+	o.cleanupsApplied.Add(o.ctx, 1, o.attrs)
+}
+
+func (o *Observer) OnDrop(string) {
+	o.drops.Add(o.ctx, 1, o.attrs)
+}
@@ -0,0 +1,67 @@
+package otelobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+func TestObserver(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	ctx, span := tracer.Start(context.Background(), "speak")
+	observer, err := New(ctx, meter)
+	require.NoError(t, err)
+
+	config := stream2sentence.DefaultConfig()
+	config.Observer = observer
+
+	chunks := []string{"Hello there, ", "this is a test. ", "Another sentence follows here. ", "Done."}
+	var sentences []string
+	for sentence := range stream2sentence.GenerateSentencesFromSlice(chunks, stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	}) {
+		sentences = append(sentences, sentence)
+	}
+	span.End()
+
+	require.Len(t, spans.Ended(), 1)
+	events := map[string]int{}
+	for _, event := range spans.Ended()[0].Events() {
+		events[event.Name]++
+	}
+	assert.Equal(t, map[string]int{SentenceEvent: len(sentences), FlushEvent: 1, ForcedBreakEvent: 1}, events)
+
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+
+	counts := map[string]int64{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				counts[m.Name] = d.DataPoints[0].Value
+			case metricdata.Histogram[int64]:
+				counts[m.Name] = int64(d.DataPoints[0].Count)
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		ChunksMetric:         int64(len(chunks)),
+		SentencesMetric:      int64(len(sentences)),
+		SentenceLengthMetric: int64(len(sentences)),
+		FlushesMetric:        1,
+		ForcedBreaksMetric:   1,
+	}, counts)
+}
@@ -0,0 +1,140 @@
+package stream2sentence
+
+import (
+	"unicode/utf8"
+)
+
+// DefaultOutputBuffer is the capacity of sentence channels when
+// OutputConfig.Buffer is unset
+const DefaultOutputBuffer = 10
+
+// LagPolicy decides what happens to a new sentence when the consumer lags
+// behind and the output buffer is full
+type LagPolicy int
+
+const (
+	// LagBlock waits until the consumer makes room
+	LagBlock LagPolicy = iota
+
+	// LagDropOldest discards the oldest queued sentence to make room
+	LagDropOldest
+
+	// LagCoalesce merges adjacent queued sentences into longer ones, so a
+	// slow consumer such as a TTS engine gets fewer, longer requests
+	LagCoalesce
+)
+
+// String returns the name of the policy
+func (p LagPolicy) String() string {
+	switch p {
+	case LagBlock:
+		return "block"
+	case LagDropOldest:
+		return "drop-oldest"
+	case LagCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// OutputConfig controls how sentences are delivered on the channels returned
+// by Stream, Flush and GenerateSentencesAsync
+type OutputConfig struct {
+	// Buffer is the capacity of the sentence channels, DefaultOutputBuffer
+	// if zero
+	Buffer int
+
+	// LagPolicy applies when the buffer is full
+	LagPolicy LagPolicy
+
+	// CoalesceLength caps the length in characters of a sentence merged by
+	// LagCoalesce. Zero means no limit. If no queued sentences can be merged
+	// within the limit, the producer waits as with LagBlock.
+	CoalesceLength int
+}
+
+// buffer returns the capacity of the sentence channels
+func (c OutputConfig) buffer() int {
+	if c.Buffer <= 0 {
+		return DefaultOutputBuffer
+	}
+	return c.Buffer
+}
+
+// deliver sends text to results, applying the lag policy if results is
+// full. wait is called to send text when the producer has to block, drop
+// with every sentence discarded by LagDropOldest. Only one goroutine may
+// send to results.
+func (c OutputConfig) deliver(results chan string, text string, wait func(string) error, drop func(string)) error {
+	select {
+	case results <- text:
+		return nil
+	default:
+	}
+
+	switch c.LagPolicy {
+	case LagDropOldest:
+		// The consumer only takes sentences out, so there is room once one
+		// is dropped
+		select {
+		case dropped := <-results:
+			drop(dropped)
+		default:
+		}
+		select {
+		case results <- text:
+			return nil
+		default:
+		}
+	case LagCoalesce:
+		if c.coalesce(results, text) {
+			return nil
+		}
+	}
+
+	return wait(text)
+}
+
+// coalesce takes the queued sentences out of results and puts them back
+// merged with text. It reports false, with the queue unchanged, if nothing
+// could be merged; text is then still to be sent.
+func (c OutputConfig) coalesce(results chan string, text string) bool {
+	var queued []string
+drain:
+	for {
+		select {
+		case sentence := <-results:
+			queued = append(queued, sentence)
+		default:
+			break drain
+		}
+	}
+
+	merged := mergeAdjacent(append(queued, text), c.CoalesceLength)
+	coalesced := len(merged) <= len(queued)
+	if !coalesced {
+		merged = queued
+	}
+
+	// At most as many sentences as were taken out, so these never block
+	for _, sentence := range merged {
+		results <- sentence
+	}
+	return coalesced
+}
+
+// mergeAdjacent joins neighbouring sentences with a space as long as the
+// result stays within limit characters, or without limit if it is zero
+func mergeAdjacent(sentences []string, limit int) []string {
+	merged := []string{sentences[0]}
+	for _, sentence := range sentences[1:] {
+		last := &merged[len(merged)-1]
+		if limit > 0 && utf8.RuneCountInString(*last)+1+utf8.RuneCountInString(sentence) > limit {
+			merged = append(merged, sentence)
+			continue
+		}
+		*last += " " + sentence
+	}
+	return merged
+}
@@ -0,0 +1,141 @@
+package stream2sentence
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+// laggingSplitter returns a splitter holding six sentences, of which Stream
+// yields the first five
+func laggingSplitter(output OutputConfig) (*SentenceSplitter, string) {
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	config.Output = output
+
+	text := strings.Join(numberedSentences(6), " ")
+	splitter := NewSentenceSplitter(config)
+	splitter.Add(text)
+	return splitter, text
+}
+
+// streamUnread processes the input while nothing is read, as if the
+// consumer lagged behind the whole stream, and returns the queued sentences
+func streamUnread(splitter *SentenceSplitter) []string {
+	results := make(chan string, splitter.output.buffer())
+	splitter.process(splitter.sendTo(results))
+	close(results)
+	return collectSentences(results)
+}
+
+// === Output Buffer Tests ===
+
+func TestOutputBuffer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	splitter := NewSentenceSplitter(DefaultConfig())
+	assert.Equal(t, DefaultOutputBuffer, cap(splitter.Stream()))
+
+	config := DefaultConfig()
+	config.Output.Buffer = 3
+	splitter = NewSentenceSplitter(config)
+	assert.Equal(t, 3, cap(splitter.Stream()))
+	assert.Equal(t, 3, cap(splitter.Flush()))
+
+	generator := make(chan string)
+	close(generator)
+	results := GenerateSentencesAsync(context.Background(), generator, GenerateSentencesConfig{SentenceSplitterConfig: config})
+	assert.Equal(t, 3, cap(results))
+	collectSentences(results)
+}
+
+// === Lag Policy Tests ===
+
+func TestLagBlock(t *testing.T) {
+	splitter, _ := laggingSplitter(OutputConfig{Buffer: 1})
+
+	sentences := collectSentences(splitter.Stream())
+	assert.Equal(t, numberedSentences(5), sentences)
+}
+
+func TestLagDropOldest(t *testing.T) {
+	splitter, _ := laggingSplitter(OutputConfig{Buffer: 2, LagPolicy: LagDropOldest})
+
+	// The producer never blocks, so the newest sentences are left
+	assert.Equal(t, numberedSentences(5)[3:], streamUnread(splitter))
+}
+
+func TestLagDropOldestReported(t *testing.T) {
+	observer := &recordingObserver{}
+	metrics := &Metrics{}
+
+	config := DefaultConfig()
+	config.QuickYieldMode = NoQuickYield
+	config.Output = OutputConfig{Buffer: 2, LagPolicy: LagDropOldest}
+	config.Observer = observer
+	config.Metrics = metrics
+	splitter := NewSentenceSplitter(config)
+	splitter.Add(strings.Join(numberedSentences(6), " "))
+
+	streamUnread(splitter)
+	assert.Equal(t, numberedSentences(3), observer.drops)
+	assert.Equal(t, 3, metrics.Stats().Dropped)
+
+	remainder := splitter.Interrupt()
+	assert.Equal(t, 3, remainder.Dropped)
+	assert.Equal(t, numberedSentences(6)[5], remainder.Text())
+	assert.Zero(t, splitter.Interrupt().Dropped)
+}
+
+func TestLagCoalesce(t *testing.T) {
+	splitter, _ := laggingSplitter(OutputConfig{Buffer: 2, LagPolicy: LagCoalesce})
+
+	assert.Equal(t, []string{strings.Join(numberedSentences(5), " ")}, streamUnread(splitter))
+}
+
+func TestLagCoalesceLength(t *testing.T) {
+	// No two sentences fit into the limit, so the producer waits
+	splitter, _ := laggingSplitter(OutputConfig{Buffer: 1, LagPolicy: LagCoalesce, CoalesceLength: 50})
+	assert.Equal(t, numberedSentences(5), collectSentences(splitter.Stream()))
+}
+
+func TestLagCoalesceInterrupt(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	splitter, text := laggingSplitter(OutputConfig{Buffer: 1, LagPolicy: LagCoalesce, CoalesceLength: 50})
+
+	results := splitter.Stream()
+	first := <-results
+	remainder := splitter.Interrupt()
+	collectSentences(results)
+
+	assert.Equal(t, words(text), words(first+" "+remainder.Text()))
+}
+
+func TestMergeAdjacent(t *testing.T) {
+	sentences := []string{"One.", "Two.", "A longer third one.", "Four."}
+
+	assert.Equal(t, []string{"One. Two. A longer third one. Four."}, mergeAdjacent(sentences, 0))
+	assert.Equal(t, []string{"One. Two.", "A longer third one.", "Four."}, mergeAdjacent(sentences, 12))
+	assert.Equal(t, []string{"One. Two.", "A longer third one. Four."}, mergeAdjacent(sentences, 25))
+	assert.Equal(t, sentences, mergeAdjacent(sentences, 1))
+}
+
+func TestLagPolicyString(t *testing.T) {
+	assert.Equal(t, "block", LagBlock.String())
+	assert.Equal(t, "drop-oldest", LagDropOldest.String())
+	assert.Equal(t, "coalesce", LagCoalesce.String())
+	assert.Equal(t, "unknown", LagPolicy(7).String())
+}
+
+func TestValidateOutput(t *testing.T) {
+	config := DefaultConfig()
+	config.Output = OutputConfig{Buffer: -1, LagPolicy: LagPolicy(7), CoalesceLength: -2}
+
+	err := config.Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Equal(t, []string{"Output.Buffer", "Output.LagPolicy", "Output.CoalesceLength"}, configErrorFields(err))
+}
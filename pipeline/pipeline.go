@@ -0,0 +1,293 @@
+// Package pipeline connects sentence splitting to speech synthesis and
+// playback. Sentences are synthesized ahead while earlier ones play, and
+// always played in order.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// ErrBargeIn is returned by Run when the run was stopped by BargeIn
+var ErrBargeIn = errors.New("pipeline: barge-in")
+
+// ErrRunning is returned by Run when the pipeline is already running
+var ErrRunning = errors.New("pipeline: already running")
+
+// AudioChunk is a piece of synthesized audio
+type AudioChunk struct {
+	// Data holds the encoded audio, e.g. PCM samples
+	Data []byte
+
+	// Duration is how long the chunk plays
+	Duration time.Duration
+}
+
+// AudioStream produces the audio of a sentence. Next returns io.EOF once
+// the sentence is complete and any other error if synthesis failed.
+type AudioStream interface {
+	Next(ctx context.Context) (AudioChunk, error)
+}
+
+// Synthesizer converts sentences to speech. Synthesize may be called for a
+// sentence while the audio of the previous one is still being read, and
+// ctx is cancelled when the sentence's audio is no longer needed.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, sentence stream2sentence.Sentence) (AudioStream, error)
+}
+
+// Player plays audio. Play returns once the chunk has been played, or
+// accepted by a device buffer of bounded size, and should return promptly
+// once ctx is done.
+type Player interface {
+	Play(ctx context.Context, chunk AudioChunk) error
+}
+
+// Config holds the pipeline options
+type Config struct {
+	// Generate configures the sentence splitter
+	Generate stream2sentence.GenerateSentencesConfig
+
+	Synthesizer Synthesizer
+	Player      Player
+
+	// Lookahead is the number of sentences synthesized ahead of the one
+	// playing, 1 by default
+	Lookahead int
+
+	// OnPlayed, if set, is called after a sentence has been played
+	// completely. Sentences cut off by BargeIn are not reported.
+	OnPlayed func(stream2sentence.Sentence)
+}
+
+// Pipeline splits text into sentences, synthesizes them and plays the
+// audio. A Pipeline runs one stream at a time; call Run again for the next.
+type Pipeline struct {
+	config Config
+
+	mu     sync.Mutex
+	cancel context.CancelCauseFunc
+}
+
+// New creates a Pipeline from the given configuration
+func New(config Config) *Pipeline {
+	if config.Lookahead <= 0 {
+		config.Lookahead = 1
+	}
+	return &Pipeline{config: config}
+}
+
+// job is the synthesis of a single sentence. Its audio is collected as it
+// arrives, so synthesis never waits for playback.
+type job struct {
+	sentence stream2sentence.Sentence
+
+	mu     sync.Mutex
+	chunks []AudioChunk
+	err    error
+	done   bool
+
+	// ready holds a pending wake-up for the player
+	ready chan struct{}
+}
+
+// Run splits the text of src, synthesizes the sentences and plays them in
+// order until src ends and all audio is played. It returns ErrBargeIn if
+// BargeIn was called, the context's error if ctx is done and otherwise the
+// first error of the source, synthesizer or player. Sentences that were
+// complete before a source error are still played. Run does not wait for a
+// Source blocked in Next that ignores ctx, like those reading an HTTP body;
+// its goroutine ends once Next returns, e.g. when the body is closed.
+func (p *Pipeline) Run(ctx context.Context, src stream2sentence.Source) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.mu.Unlock()
+		return ErrRunning
+	}
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.cancel = nil
+		p.mu.Unlock()
+	}()
+
+	var (
+		queue     = make(chan *job, p.config.Lookahead)
+		sourceErr error
+
+		// Synthesis honors ctx and is waited for, unlike the source.
+		// stopped keeps new synthesis from starting once Run is leaving.
+		synthesis sync.WaitGroup
+		startMu   sync.Mutex
+		stopped   bool
+	)
+
+	startSynthesis := func(j *job) {
+		startMu.Lock()
+		defer startMu.Unlock()
+		if stopped {
+			return
+		}
+
+		synthesis.Add(1)
+		go func() {
+			defer synthesis.Done()
+			p.synthesize(ctx, j)
+		}()
+	}
+
+	go func() {
+		defer close(queue)
+
+		for sentence, err := range stream2sentence.SentencesFromSource(ctx, src, p.config.Generate) {
+			if err != nil {
+				sourceErr = err
+				return
+			}
+
+			j := &job{sentence: sentence, ready: make(chan struct{}, 1)}
+			select {
+			case queue <- j:
+			case <-ctx.Done():
+				return
+			}
+
+			startSynthesis(j)
+		}
+	}()
+
+	err := p.play(ctx, queue)
+	if err != nil {
+		cancel(err)
+	}
+
+	startMu.Lock()
+	stopped = true
+	startMu.Unlock()
+	synthesis.Wait()
+
+	if cause := context.Cause(ctx); errors.Is(cause, ErrBargeIn) {
+		return ErrBargeIn
+	}
+	if err != nil {
+		return err
+	}
+
+	// The queue was closed, so the source goroutine has finished
+	return sourceErr
+}
+
+// BargeIn stops the running pipeline at once: playback is cut off, pending
+// synthesis is cancelled and Run returns ErrBargeIn. It does nothing if the
+// pipeline is not running.
+func (p *Pipeline) BargeIn() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel(ErrBargeIn)
+	}
+}
+
+// synthesize collects the audio of a job's sentence
+func (p *Pipeline) synthesize(ctx context.Context, j *job) {
+	var err error
+	defer func() {
+		j.mu.Lock()
+		j.err, j.done = err, true
+		j.mu.Unlock()
+		j.wake()
+	}()
+
+	stream, err := p.config.Synthesizer.Synthesize(ctx, j.sentence)
+	if err != nil {
+		err = fmt.Errorf("pipeline: synthesizing sentence %d: %w", j.sentence.Index, err)
+		return
+	}
+
+	for {
+		var chunk AudioChunk
+		chunk, err = stream.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+		if err != nil {
+			err = fmt.Errorf("pipeline: synthesizing sentence %d: %w", j.sentence.Index, err)
+			return
+		}
+
+		j.mu.Lock()
+		j.chunks = append(j.chunks, chunk)
+		j.mu.Unlock()
+		j.wake()
+	}
+}
+
+// wake signals the player without blocking
+func (j *job) wake() {
+	select {
+	case j.ready <- struct{}{}:
+	default:
+	}
+}
+
+// play plays the queued jobs in order until the queue is closed
+func (p *Pipeline) play(ctx context.Context, queue <-chan *job) error {
+	for {
+		var j *job
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case next, ok := <-queue:
+			if !ok {
+				return ctx.Err()
+			}
+			j = next
+		}
+
+		for {
+			j.mu.Lock()
+			chunks, done, err := j.chunks, j.done, j.err
+			j.chunks = nil
+			j.mu.Unlock()
+
+			for _, chunk := range chunks {
+				if err := p.config.Player.Play(ctx, chunk); err != nil {
+					return err
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+
+			select {
+			case <-j.ready:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if p.config.OnPlayed != nil {
+			p.config.OnPlayed(j.sentence)
+		}
+	}
+}
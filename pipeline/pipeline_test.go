@@ -0,0 +1,435 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/caption"
+)
+
+// textSynthesizer returns the sentence text as a single audio chunk after a
+// delay chosen per sentence
+type textSynthesizer struct {
+	delay   func(sentence stream2sentence.Sentence) time.Duration
+	started chan int
+	err     error
+}
+
+func (s *textSynthesizer) Synthesize(ctx context.Context, sentence stream2sentence.Sentence) (AudioStream, error) {
+	if s.started != nil {
+		s.started <- sentence.Index
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	sent := false
+	return audioStreamFunc(func(ctx context.Context) (AudioChunk, error) {
+		if sent {
+			return AudioChunk{}, io.EOF
+		}
+		if s.delay != nil {
+			select {
+			case <-time.After(s.delay(sentence)):
+			case <-ctx.Done():
+				return AudioChunk{}, ctx.Err()
+			}
+		}
+		sent = true
+		return AudioChunk{Data: []byte(sentence.Text), Duration: time.Millisecond}, nil
+	}), nil
+}
+
+// audioStreamFunc adapts a function to the AudioStream interface
+type audioStreamFunc func(ctx context.Context) (AudioChunk, error)
+
+func (f audioStreamFunc) Next(ctx context.Context) (AudioChunk, error) {
+	return f(ctx)
+}
+
+// recordingPlayer records the played chunks, calling block first if set
+type recordingPlayer struct {
+	mu     sync.Mutex
+	played []string
+	block  func(ctx context.Context, chunk AudioChunk) error
+}
+
+func (p *recordingPlayer) Play(ctx context.Context, chunk AudioChunk) error {
+	if p.block != nil {
+		if err := p.block(ctx, chunk); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.played = append(p.played, string(chunk.Data))
+	return nil
+}
+
+func (p *recordingPlayer) Played() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.played
+}
+
+// sentenceSource returns a Source sending each sentence as its own chunk
+func sentenceSource(sentences ...string) stream2sentence.Source {
+	chunks := make(chan string, len(sentences))
+	for _, sentence := range sentences {
+		chunks <- sentence + " "
+	}
+	close(chunks)
+	return stream2sentence.ChannelSource(chunks)
+}
+
+// generateConfig splits at every full sentence
+func generateConfig() stream2sentence.GenerateSentencesConfig {
+	config := stream2sentence.DefaultConfig()
+	config.QuickYieldMode = stream2sentence.NoQuickYield
+	return stream2sentence.GenerateSentencesConfig{SentenceSplitterConfig: config}
+}
+
+var testSentences = []string{
+	"The first sentence is here.",
+	"The second one follows.",
+	"A third sentence comes next.",
+	"And the fourth ends it.",
+}
+
+// === Pipeline Tests ===
+
+func TestPipelineOrder(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// Later sentences finish synthesis first
+	synthesizer := &textSynthesizer{delay: func(sentence stream2sentence.Sentence) time.Duration {
+		return time.Duration(len(testSentences)-sentence.Index) * 10 * time.Millisecond
+	}}
+	player := &recordingPlayer{}
+
+	var reported []string
+	p := New(Config{
+		Generate:    generateConfig(),
+		Synthesizer: synthesizer,
+		Player:      player,
+		Lookahead:   3,
+		OnPlayed: func(sentence stream2sentence.Sentence) {
+			reported = append(reported, sentence.Text)
+		},
+	})
+
+	require.NoError(t, p.Run(context.Background(), sentenceSource(testSentences...)))
+	assert.Equal(t, testSentences, player.Played())
+	assert.Equal(t, testSentences, reported)
+}
+
+func TestPipelineSynthesizesAhead(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	started := make(chan int, len(testSentences))
+	synthesizer := &textSynthesizer{started: started}
+
+	// Playback of the first sentence only finishes once the synthesis of
+	// the second has started
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		if string(chunk.Data) != testSentences[0] {
+			return nil
+		}
+		for {
+			select {
+			case index := <-started:
+				if index == 1 {
+					return nil
+				}
+			case <-time.After(5 * time.Second):
+				return errors.New("second sentence was not synthesized during playback")
+			}
+		}
+	}}
+
+	p := New(Config{Generate: generateConfig(), Synthesizer: synthesizer, Player: player})
+	require.NoError(t, p.Run(context.Background(), sentenceSource(testSentences...)))
+	assert.Equal(t, testSentences, player.Played())
+}
+
+func TestPipelineLookaheadLimit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	started := make(chan int, len(testSentences))
+	release := make(chan struct{})
+	synthesizer := &textSynthesizer{started: started}
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		<-release
+		return nil
+	}}
+
+	p := New(Config{Generate: generateConfig(), Synthesizer: synthesizer, Player: player, Lookahead: 1})
+
+	done := make(chan error, 1)
+	go func() { done <- p.Run(context.Background(), sentenceSource(testSentences...)) }()
+
+	// The playing sentence and one more are synthesized, no further
+	assert.ElementsMatch(t, []int{0, 1}, []int{<-started, <-started})
+	select {
+	case index := <-started:
+		t.Errorf("sentence %d synthesized beyond the lookahead", index)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, testSentences, player.Played())
+}
+
+func TestPipelineBargeIn(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	playing := make(chan struct{})
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		if string(chunk.Data) == testSentences[1] {
+			close(playing)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}}
+
+	var reported []string
+	p := New(Config{
+		Generate:    generateConfig(),
+		Synthesizer: &textSynthesizer{},
+		Player:      player,
+		OnPlayed: func(sentence stream2sentence.Sentence) {
+			reported = append(reported, sentence.Text)
+		},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- p.Run(context.Background(), sentenceSource(testSentences...)) }()
+
+	<-playing
+	p.BargeIn()
+
+	assert.ErrorIs(t, <-done, ErrBargeIn)
+	assert.Equal(t, testSentences[:1], player.Played())
+	assert.Equal(t, testSentences[:1], reported)
+
+	// The pipeline can run again after a barge-in
+	player.block = nil
+	require.NoError(t, p.Run(context.Background(), sentenceSource("Sorry, go ahead.")))
+}
+
+func TestPipelineBargeInBlockedSource(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// The source ignores ctx, like one reading an HTTP body, and blocks
+	// after the first sentence until it is released
+	release := make(chan struct{})
+	sent := false
+	src := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		if !sent {
+			sent = true
+			return "The first sentence is here. And then more follows", nil
+		}
+		<-release
+		return "", io.EOF
+	})
+
+	played := make(chan struct{})
+	pipeline := New(Config{
+		Generate:    generateConfig(),
+		Synthesizer: &textSynthesizer{},
+		Player:      &recordingPlayer{},
+		OnPlayed:    func(stream2sentence.Sentence) { close(played) },
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- pipeline.Run(context.Background(), src) }()
+
+	<-played
+	pipeline.BargeIn()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrBargeIn)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after BargeIn")
+	}
+
+	// The source goroutine ends once Next returns
+	close(release)
+}
+
+func TestPipelineBargeInIdle(t *testing.T) {
+	p := New(Config{Generate: generateConfig(), Synthesizer: &textSynthesizer{}, Player: &recordingPlayer{}})
+	p.BargeIn()
+	require.NoError(t, p.Run(context.Background(), sentenceSource(testSentences...)))
+}
+
+func TestPipelineRunning(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	release := make(chan struct{})
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		<-release
+		return nil
+	}}
+	p := New(Config{Generate: generateConfig(), Synthesizer: &textSynthesizer{}, Player: player})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx, stream2sentence.ChannelSource(make(chan string))) }()
+
+	assert.Eventually(t, func() bool {
+		return errors.Is(p.Run(ctx, sentenceSource()), ErrRunning)
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	close(release)
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+// === Error Tests ===
+
+func TestPipelineSynthesisError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	failure := errors.New("voice not found")
+	p := New(Config{
+		Generate:    generateConfig(),
+		Synthesizer: &textSynthesizer{err: failure},
+		Player:      &recordingPlayer{},
+	})
+
+	err := p.Run(context.Background(), sentenceSource(testSentences...))
+	assert.ErrorIs(t, err, failure)
+	assert.Contains(t, err.Error(), "sentence 0")
+}
+
+func TestPipelinePlayerError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	failure := errors.New("device unplugged")
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		return failure
+	}}
+	p := New(Config{Generate: generateConfig(), Synthesizer: &textSynthesizer{}, Player: player})
+
+	assert.ErrorIs(t, p.Run(context.Background(), sentenceSource(testSentences...)), failure)
+}
+
+func TestPipelineSourceError(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	failure := errors.New("connection reset")
+	chunks := []string{"The first sentence is here. ", "The second one follows. ", "Cut off in the"}
+	source := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		if len(chunks) == 0 {
+			return "", failure
+		}
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk, nil
+	})
+
+	player := &recordingPlayer{}
+	p := New(Config{Generate: generateConfig(), Synthesizer: &textSynthesizer{}, Player: player})
+
+	// Complete sentences are still played before the error is returned
+	err := p.Run(context.Background(), source)
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorIs(t, err, stream2sentence.ErrUpstream)
+	assert.Equal(t, testSentences[:2], player.Played())
+}
+
+func TestPipelineCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	player := &recordingPlayer{block: func(ctx context.Context, chunk AudioChunk) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	p := New(Config{Generate: generateConfig(), Synthesizer: &textSynthesizer{}, Player: player})
+
+	assert.ErrorIs(t, p.Run(ctx, sentenceSource(testSentences...)), context.Canceled)
+	assert.Empty(t, player.Played())
+}
+
+// === Silence Synthesizer Tests ===
+
+func TestSilenceSynthesizer(t *testing.T) {
+	synthesizer := &SilenceSynthesizer{SampleRate: 8000, ChunkDuration: 250 * time.Millisecond}
+	sentence := stream2sentence.Sentence{Text: strings.Repeat("word ", 10)}
+
+	stream, err := synthesizer.Synthesize(context.Background(), sentence)
+	require.NoError(t, err)
+
+	var total time.Duration
+	for {
+		chunk, err := stream.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		assert.LessOrEqual(t, chunk.Duration, 250*time.Millisecond)
+		assert.Len(t, chunk.Data, 2*int(chunk.Duration*8000/time.Second))
+		assert.Equal(t, make([]byte, len(chunk.Data)), chunk.Data)
+		total += chunk.Duration
+	}
+
+	assert.Equal(t, caption.EstimateDuration(sentence.Text, caption.DefaultWordsPerMinute), total)
+}
+
+func TestSilenceSynthesizerLatency(t *testing.T) {
+	synthesizer := &SilenceSynthesizer{Latency: time.Hour}
+	stream, err := synthesizer.Synthesize(context.Background(), stream2sentence.Sentence{Text: "Hello."})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = stream.Next(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPipelineWithSilence(t *testing.T) {
+	var played time.Duration
+	player := &durationPlayer{played: &played}
+	p := New(Config{
+		Generate:    generateConfig(),
+		Synthesizer: &SilenceSynthesizer{Latency: time.Millisecond},
+		Player:      player,
+	})
+
+	require.NoError(t, p.Run(context.Background(), sentenceSource(testSentences...)))
+
+	var expected time.Duration
+	for _, sentence := range testSentences {
+		expected += caption.EstimateDuration(sentence, caption.DefaultWordsPerMinute)
+	}
+	assert.Equal(t, expected, played)
+}
+
+// durationPlayer adds up the played durations without waiting
+type durationPlayer struct {
+	played *time.Duration
+}
+
+func (p *durationPlayer) Play(ctx context.Context, chunk AudioChunk) error {
+	*p.played += chunk.Duration
+	return nil
+}
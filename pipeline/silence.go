@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/caption"
+)
+
+// SilenceSynthesizer is a fake Synthesizer for tests and dry runs. It
+// produces 16-bit mono PCM silence as long as the sentence would take to
+// speak, estimated with caption.EstimateDuration.
+type SilenceSynthesizer struct {
+	// SampleRate is the number of samples per second, 16000 by default
+	SampleRate int
+
+	// WordsPerMinute is the speaking rate, caption.DefaultWordsPerMinute by
+	// default
+	WordsPerMinute float64
+
+	// ChunkDuration is the length of each chunk, 100ms by default
+	ChunkDuration time.Duration
+
+	// Latency simulates the time to the first chunk
+	Latency time.Duration
+}
+
+var _ Synthesizer = (*SilenceSynthesizer)(nil)
+
+// Synthesize implements Synthesizer
+func (s *SilenceSynthesizer) Synthesize(ctx context.Context, sentence stream2sentence.Sentence) (AudioStream, error) {
+	sampleRate := s.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	chunkDuration := s.ChunkDuration
+	if chunkDuration <= 0 {
+		chunkDuration = 100 * time.Millisecond
+	}
+
+	return &silenceStream{
+		sampleRate: sampleRate,
+		chunk:      chunkDuration,
+		remaining:  caption.EstimateDuration(sentence.Text, s.WordsPerMinute),
+		latency:    s.Latency,
+	}, nil
+}
+
+// silenceStream produces chunks of silence until the duration is used up
+type silenceStream struct {
+	sampleRate int
+	chunk      time.Duration
+	remaining  time.Duration
+	latency    time.Duration
+}
+
+// Next implements AudioStream
+func (s *silenceStream) Next(ctx context.Context) (AudioChunk, error) {
+	if s.latency > 0 {
+		timer := time.NewTimer(s.latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return AudioChunk{}, ctx.Err()
+		}
+		s.latency = 0
+	}
+
+	if err := ctx.Err(); err != nil {
+		return AudioChunk{}, err
+	}
+	if s.remaining <= 0 {
+		return AudioChunk{}, io.EOF
+	}
+
+	duration := min(s.chunk, s.remaining)
+	s.remaining -= duration
+
+	samples := int(duration * time.Duration(s.sampleRate) / time.Second)
+	return AudioChunk{Data: make([]byte, 2*samples), Duration: duration}, nil
+}
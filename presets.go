@@ -0,0 +1,102 @@
+package stream2sentence
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// presets holds the named configurations returned by Preset
+var presets = map[string]func() SentenceSplitterConfig{
+	"default": DefaultConfig,
+
+	"voice-assistant": VoiceAssistantConfig,
+	"narration":       NarrationConfig,
+	"captions":        CaptionConfig,
+	"chat-typing":     ChatTypingConfig,
+
+	// Aliases. low-latency is VoiceAssistantConfig, the lowest latency
+	// preset, and high-quality and document-reading are NarrationConfig,
+	// the most accurate one.
+	"document-reading": NarrationConfig,
+	"low-latency":      VoiceAssistantConfig,
+	"high-quality":     NarrationConfig,
+}
+
+// VoiceAssistantConfig returns a preset for ultra-low latency voice
+// assistants. Every fragment is yielded as soon as possible with a small
+// context window, so speech can start after a few words at the cost of
+// more breaks mid-sentence.
+//
+// Measured on the eval gold corpora (BenchmarkPresets): precision 0.620,
+// recall 1.000, F1 0.765, first fragment after 21.6 characters.
+func VoiceAssistantConfig() SentenceSplitterConfig {
+	config := DefaultConfig()
+	config.ContextSize = 4
+	config.MinimumSentenceLength = 4
+	config.MinimumFirstFragmentLength = 2
+	return config
+}
+
+// NarrationConfig returns a preset for audiobooks and document narration.
+// It never quick-yields, splits at full sentence delimiters only and looks
+// further ahead, so sentences are complete and prosody stays natural.
+//
+// Measured on the eval gold corpora (BenchmarkPresets): precision 1.000,
+// recall 0.774, F1 0.873, first fragment after 86.9 characters.
+func NarrationConfig() SentenceSplitterConfig {
+	config := DefaultConfig()
+	config.ContextSize = 24
+	config.MinimumSentenceLength = 30
+	config.QuickYieldMode = NoQuickYield
+	config.SentenceFragmentDelimiters = config.FullSentenceDelimiters
+	return config
+}
+
+// CaptionConfig returns a preset for live subtitles and captions. Every
+// fragment is yielded so cues follow speech closely, and emojis are kept
+// since they are displayed rather than spoken. The splitter has no length
+// limit; the caption package, which uses this preset by default, wraps each
+// sentence into cues of bounded line length and line count.
+//
+// Measured on the eval gold corpora (BenchmarkPresets): precision 0.721,
+// recall 1.000, F1 0.838, first fragment after 27.8 characters.
+func CaptionConfig() SentenceSplitterConfig {
+	config := DefaultConfig()
+	config.ContextSize = 6
+	config.MinimumSentenceLength = 12
+	config.MinimumFirstFragmentLength = 6
+	config.CleanupOptions = CleanupLinks | CleanupTable | StripText
+	return config
+}
+
+// ChatTypingConfig returns a preset for revealing a chat reply with a
+// typing effect. Only the first fragment is quick-yielded so the reply
+// starts promptly, and links and emojis are kept for display.
+//
+// Measured on the eval gold corpora (BenchmarkPresets): precision 0.738,
+// recall 1.000, F1 0.849, first fragment after 27.8 characters.
+func ChatTypingConfig() SentenceSplitterConfig {
+	config := DefaultConfig()
+	config.ContextSize = 12
+	config.MinimumSentenceLength = 15
+	config.MinimumFirstFragmentLength = 5
+	config.QuickYieldMode = QuickYieldFirstFragment
+	config.CleanupOptions = StripText
+	return config
+}
+
+// Preset returns the named configuration preset. See PresetNames for the
+// available names.
+func Preset(name string) (SentenceSplitterConfig, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return SentenceSplitterConfig{}, fmt.Errorf("stream2sentence: unknown preset %q", name)
+	}
+	return preset(), nil
+}
+
+// PresetNames returns the names of all presets in sorted order
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
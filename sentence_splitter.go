@@ -2,10 +2,20 @@ package stream2sentence
 
 import (
 	"container/list"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
+// ErrClosed is reported by a splitter once it has been closed
+var ErrClosed = errors.New("stream2sentence: splitter closed")
+
 // QuickYieldMode defines different modes for quick yielding
 type QuickYieldMode int
 
@@ -15,7 +25,63 @@ const (
 	QuickYieldAllFragments
 )
 
-// SentenceSplitter processes text streams and yields well-formed sentences
+// quickYieldModeNames holds the text names of the quick yield modes
+var quickYieldModeNames = [...]string{
+	NoQuickYield:            "none",
+	QuickYieldFirstFragment: "first-fragment",
+	QuickYieldAllFragments:  "all-fragments",
+}
+
+// String returns the text name of the mode
+func (m QuickYieldMode) String() string {
+	if m < 0 || int(m) >= len(quickYieldModeNames) {
+		return fmt.Sprintf("QuickYieldMode(%d)", int(m))
+	}
+	return quickYieldModeNames[m]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (m QuickYieldMode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(quickYieldModeNames) {
+		return nil, fmt.Errorf("stream2sentence: unknown quick yield mode %d", int(m))
+	}
+	return []byte(quickYieldModeNames[m]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Besides the names
+// returned by String it accepts the short forms "first" and "all" and the
+// numeric values configurations were written with before modes had names.
+func (m *QuickYieldMode) UnmarshalText(text []byte) error {
+	switch name := strings.ToLower(strings.TrimSpace(string(text))); name {
+	case "none":
+		*m = NoQuickYield
+	case "first", "first-fragment":
+		*m = QuickYieldFirstFragment
+	case "all", "all-fragments":
+		*m = QuickYieldAllFragments
+	default:
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 0 || n >= len(quickYieldModeNames) {
+			return fmt.Errorf("stream2sentence: unknown quick yield mode %q", name)
+		}
+		*m = QuickYieldMode(n)
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a name, as
+// UnmarshalText does, or a number.
+func (m *QuickYieldMode) UnmarshalJSON(data []byte) error {
+	return unmarshalTextOrNumber(data, m)
+}
+
+// SentenceSplitter processes text streams and yields well-formed sentences.
+//
+// Add and Close may be called from any number of goroutines, also while the
+// input is being processed. All other methods consume the input and must be
+// called by a single consumer at a time, and the channel returned by Stream
+// or Flush must be drained before the next one is requested. Use
+// ConcurrentSplitter for producers and a consumer running independently.
 type SentenceSplitter struct {
 	// Core configuration
 	contextSize                int
@@ -33,12 +99,58 @@ type SentenceSplitter struct {
 	fullSentenceDelimiters     string
 
 	// Internal state
+	inputMu               sync.Mutex
 	inputBuffer           *list.List
 	buffer                strings.Builder
 	isFirstSentence       bool
 	wordCount             int
 	lastDelimiterPosition int
 
+	// Callbacks and the first error that stopped processing
+	callbacks Callbacks
+	err       error
+	emitted   int
+
+	// Delivery options of the result channels
+	output OutputConfig
+
+	// Adaptive tuning. The configured minimum sentence length and quick
+	// yield mode are kept as the idle bounds, while the fields above hold
+	// the values in effect. backlog is guarded by signalMu.
+	adaptive                  AdaptiveConfig
+	laggingQuickYield         bool
+	laggingQuickYieldMode     QuickYieldMode
+	idleMinimumSentenceLength int
+	idleQuickYieldMode        QuickYieldMode
+	reportedDepth             atomic.Int64
+	backlog                   func() int
+
+	// Closed by Close to release goroutines blocked on result channels
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// Interruption state. processMu is held while the input is processed
+	// and while Reset, Reconfigure, Snapshot and Restore touch the buffers,
+	// so Interrupt can wait for them to finish, and interruptMu
+	// serializes Interrupt calls. interrupted is closed to release blocked
+	// sends and replaced once the interruption is over; it and results are
+	// guarded by signalMu.
+	processMu    sync.Mutex
+	interruptMu  sync.Mutex
+	interrupting atomic.Bool
+	signalMu     sync.Mutex
+	interrupted  chan struct{}
+	results      chan string
+	cutOff       []string
+	dropped      int
+
+	// Instrumentation, latency is only tracked when metrics are enabled
+	observer    Observer
+	metrics     *Metrics
+	consumed    int
+	arrivals    []arrival
+	streamStart time.Time
+
 	// Character sets for fast lookup
 	fragmentDelimiterSet map[rune]bool
 	fullDelimiterSet     map[rune]bool
@@ -46,13 +158,36 @@ type SentenceSplitter struct {
 
 // SentenceSplitterConfig holds configuration options for SentenceSplitter
 type SentenceSplitterConfig struct {
-	ContextSize                int
-	MinimumSentenceLength      int
-	MinimumFirstFragmentLength int
-	QuickYieldMode             QuickYieldMode
-	CleanupOptions             CleanupFlags
-	SentenceFragmentDelimiters string
-	FullSentenceDelimiters     string
+	ContextSize                int            `json:"context_size" yaml:"context_size"`
+	MinimumSentenceLength      int            `json:"minimum_sentence_length" yaml:"minimum_sentence_length"`
+	MinimumFirstFragmentLength int            `json:"minimum_first_fragment_length" yaml:"minimum_first_fragment_length"`
+	QuickYieldMode             QuickYieldMode `json:"quick_yield_mode" yaml:"quick_yield_mode"`
+	CleanupOptions             CleanupFlags   `json:"cleanup_options" yaml:"cleanup_options"`
+	SentenceFragmentDelimiters string         `json:"sentence_fragment_delimiters" yaml:"sentence_fragment_delimiters"`
+	FullSentenceDelimiters     string         `json:"full_sentence_delimiters" yaml:"full_sentence_delimiters"`
+
+	// Metrics, if set, records per-sentence timing and aggregate latency stats
+	Metrics *Metrics `json:"-" yaml:"-"`
+
+	// Observer, if set, is notified of chunks, sentences and flushes
+	Observer Observer `json:"-" yaml:"-"`
+
+	// Callbacks are invoked synchronously as text is processed
+	Callbacks Callbacks `json:"-" yaml:"-"`
+
+	// Output controls the buffering of sentence channels and what happens
+	// when their consumer lags behind
+	Output OutputConfig `json:"-" yaml:"-"`
+
+	// Adaptive, if its HighWater is set, adjusts MinimumSentenceLength and
+	// QuickYieldMode to the consumer's backlog
+	Adaptive AdaptiveConfig `json:"-" yaml:"-"`
+}
+
+// inputChunk is a text chunk waiting in the input buffer
+type inputChunk struct {
+	text      string
+	arrivedAt time.Time
 }
 
 // DefaultConfig returns a default configuration for SentenceSplitter
@@ -68,17 +203,16 @@ func DefaultConfig() SentenceSplitterConfig {
 	}
 }
 
-// NewSentenceSplitter creates a new SentenceSplitter with the given configuration
+// NewSentenceSplitter creates a new SentenceSplitter with the given configuration.
+// The configuration is used as is; NewSentenceSplitterE validates it first.
 func NewSentenceSplitter(config SentenceSplitterConfig) *SentenceSplitter {
 
 	splitter := &SentenceSplitter{
-		contextSize:                config.ContextSize,
-		minimumSentenceLength:      config.MinimumSentenceLength,
-		minimumFirstFragmentLength: config.MinimumFirstFragmentLength,
-		quickYieldMode:             config.QuickYieldMode,
-		cleanupOptions:             config.CleanupOptions,
-		sentenceFragmentDelimiters: config.SentenceFragmentDelimiters,
-		fullSentenceDelimiters:     config.FullSentenceDelimiters,
+		metrics:   config.Metrics,
+		observer:  config.Observer,
+		callbacks: config.Callbacks,
+		output:    config.Output,
+		adaptive:  config.Adaptive,
 
 		// Initialize internal state
 		inputBuffer:           list.New(),
@@ -86,175 +220,542 @@ func NewSentenceSplitter(config SentenceSplitterConfig) *SentenceSplitter {
 		isFirstSentence:       true,
 		wordCount:             0,
 		lastDelimiterPosition: -1,
+		done:                  make(chan struct{}),
+		interrupted:           make(chan struct{}),
+	}
 
-		// Initialize delimiter sets
-		fragmentDelimiterSet: make(map[rune]bool),
-		fullDelimiterSet:     make(map[rune]bool),
+	// The lagging mode is copied, so later changes through the pointer
+	// have no effect
+	if mode := config.Adaptive.LaggingQuickYieldMode; mode != nil {
+		splitter.laggingQuickYield = true
+		splitter.laggingQuickYieldMode = *mode
+		splitter.adaptive.LaggingQuickYieldMode = nil
 	}
 
+	if splitter.observer == nil {
+		splitter.observer = NopObserver{}
+	}
+
+	splitter.applyConfig(config)
+
+	return splitter
+}
+
+// applyConfig sets the tuning options of config, leaving its metrics,
+// observer and callbacks aside
+func (s *SentenceSplitter) applyConfig(config SentenceSplitterConfig) {
+	s.contextSize = config.ContextSize
+	s.minimumSentenceLength = config.MinimumSentenceLength
+	s.minimumFirstFragmentLength = config.MinimumFirstFragmentLength
+	s.quickYieldMode = config.QuickYieldMode
+	s.idleMinimumSentenceLength = config.MinimumSentenceLength
+	s.idleQuickYieldMode = config.QuickYieldMode
+	s.cleanupOptions = config.CleanupOptions
+	s.sentenceFragmentDelimiters = config.SentenceFragmentDelimiters
+	s.fullSentenceDelimiters = config.FullSentenceDelimiters
+
 	// Populate delimiter sets for fast lookup
+	s.fragmentDelimiterSet = make(map[rune]bool)
 	for _, r := range config.SentenceFragmentDelimiters {
-		splitter.fragmentDelimiterSet[r] = true
+		s.fragmentDelimiterSet[r] = true
 	}
 
+	s.fullDelimiterSet = make(map[rune]bool)
 	for _, r := range config.FullSentenceDelimiters {
-		splitter.fullDelimiterSet[r] = true
+		s.fullDelimiterSet[r] = true
 	}
+}
 
-	return splitter
+// Reset clears the buffered text, pending input and all internal state, as
+// well as an error that stopped processing, so the splitter can start a new
+// stream. A closed splitter stays closed.
+func (s *SentenceSplitter) Reset() {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	s.inputMu.Lock()
+	s.inputBuffer.Init()
+	s.inputMu.Unlock()
+
+	s.clearBuffer()
+	s.err = nil
+	s.emitted = 0
+	s.consumed = 0
+	s.dropped = 0
+}
+
+// Reconfigure applies the tuning options of config, such as delimiters,
+// lengths and cleanup flags, to the following input without dropping the
+// buffered text. The Metrics, Observer, Callbacks, Output and Adaptive
+// options of config are ignored. If config is invalid, the error from
+// Validate is returned and the current configuration is kept.
+func (s *SentenceSplitter) Reconfigure(config SentenceSplitterConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	s.applyConfig(config)
+	return nil
+}
+
+// tuningConfig returns the tuning options the splitter is configured with,
+// before any adaptation
+func (s *SentenceSplitter) tuningConfig() SentenceSplitterConfig {
+	return SentenceSplitterConfig{
+		ContextSize:                s.contextSize,
+		MinimumSentenceLength:      s.idleMinimumSentenceLength,
+		MinimumFirstFragmentLength: s.minimumFirstFragmentLength,
+		QuickYieldMode:             s.idleQuickYieldMode,
+		CleanupOptions:             s.cleanupOptions,
+		SentenceFragmentDelimiters: s.sentenceFragmentDelimiters,
+		FullSentenceDelimiters:     s.fullSentenceDelimiters,
+	}
 }
 
 // Add adds a text chunk to the input buffer
 func (s *SentenceSplitter) Add(chunk string) {
-	s.inputBuffer.PushBack(chunk)
+	s.observer.OnChunk(chunk)
+
+	pending := inputChunk{text: chunk}
+	if s.metrics != nil {
+		pending.arrivedAt = time.Now()
+	}
+
+	s.inputMu.Lock()
+	s.inputBuffer.PushBack(pending)
+	s.inputMu.Unlock()
+}
+
+// nextChunk removes and returns the oldest chunk of the input buffer
+func (s *SentenceSplitter) nextChunk() (inputChunk, bool) {
+	s.inputMu.Lock()
+	defer s.inputMu.Unlock()
+
+	element := s.inputBuffer.Front()
+	if element == nil {
+		return inputChunk{}, false
+	}
+	s.inputBuffer.Remove(element)
+	return element.Value.(inputChunk), true
 }
 
-// Stream processes the input buffer and yields sentences
+// Stream processes the input buffer and yields sentences. The channel must
+// be drained, or the splitter closed, to release the goroutine feeding it.
 func (s *SentenceSplitter) Stream() <-chan string {
-	resultChan := make(chan string, 10) // Buffered channel
+	resultChan := make(chan string, s.output.buffer())
+	s.track(resultChan)
 
 	go func() {
 		defer close(resultChan)
+		s.process(s.sendTo(resultChan))
+	}()
 
-		for s.inputBuffer.Len() > 0 {
-			element := s.inputBuffer.Front()
-			s.inputBuffer.Remove(element)
-			chunk := element.Value.(string)
+	return resultChan
+}
 
-			for _, char := range chunk {
-				if char == 0 {
-					continue
+// Flush yields remaining buffer as final sentence(s). The channel must be
+// drained, or the splitter closed, to release the goroutine feeding it.
+func (s *SentenceSplitter) Flush() <-chan string {
+	resultChan := make(chan string, s.output.buffer())
+	s.track(resultChan)
+
+	go func() {
+		defer close(resultChan)
+		s.flush(s.sendTo(resultChan))
+	}()
+
+	return resultChan
+}
+
+// Close stops the splitter. Goroutines blocked sending on channels returned
+// by Stream and Flush are released, and later calls yield nothing and report
+// ErrClosed. Close may be called from any goroutine and more than once.
+func (s *SentenceSplitter) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+// closed reports whether Close has been called
+func (s *SentenceSplitter) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendTo returns an emit function sending sentence texts to resultChan,
+// following the lag policy, until the splitter is closed or interrupted
+func (s *SentenceSplitter) sendTo(resultChan chan string) func(Sentence) error {
+	wait := func(text string) error {
+		select {
+		case resultChan <- text:
+			return nil
+		case <-s.done:
+			return ErrClosed
+		case <-s.interruptSignal():
+			return ErrInterrupted
+		}
+	}
+
+	return func(sentence Sentence) error {
+		return s.output.deliver(resultChan, sentence.Text, wait, s.drop)
+	}
+}
+
+// drop reports a sentence discarded by the lag policy
+func (s *SentenceSplitter) drop(sentence string) {
+	s.dropped++
+	s.observer.OnDrop(sentence)
+	if s.metrics != nil {
+		s.metrics.recordDrop()
+	}
+}
+
+// process consumes the input buffer and passes every yielded sentence to emit,
+// which may be nil. It stops at the first error returned by a callback or emit.
+func (s *SentenceSplitter) process(emit func(Sentence) error) error {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	if s.err == nil && s.closed() {
+		s.err = ErrClosed
+	}
+
+	for s.err == nil {
+		chunk, ok := s.nextChunk()
+		if !ok {
+			break
+		}
+		s.adapt()
+
+		for i, char := range chunk.text {
+			if s.interrupting.Load() {
+				s.unread(inputChunk{text: chunk.text[i:], arrivedAt: chunk.arrivedAt})
+				return ErrInterrupted
+			}
+
+			if char == 0 {
+				continue
+			}
+
+			// unreadRest returns the rest of the chunk to the input buffer
+			// if emission was interrupted
+			unreadRest := func(err error) {
+				if errors.Is(err, ErrInterrupted) {
+					_, size := utf8.DecodeRuneInString(chunk.text[i:])
+					s.unread(inputChunk{text: chunk.text[i+size:], arrivedAt: chunk.arrivedAt})
 				}
+			}
 
-				// Add character to buffer and trim left whitespace
-				s.buffer.WriteRune(char)
-				bufferStr := strings.TrimLeft(s.buffer.String(), " \t\n\r")
-				s.buffer.Reset()
-				s.buffer.WriteString(bufferStr)
+			// A link is complete at the whitespace after it and dropped
+			// right away, so it is never cut into separate sentences
+			if unicode.IsSpace(char) && s.cleanupOptions.HasFlag(CleanupLinks) {
+				s.dropTrailingURL()
+			}
+
+			// Add character to buffer and trim left whitespace
+			s.buffer.WriteRune(char)
+			bufferStr := strings.TrimLeft(s.buffer.String(), " \t\n\r")
+			s.buffer.Reset()
+			s.buffer.WriteString(bufferStr)
+
+			if s.metrics != nil {
+				s.trackArrival(char, chunk.arrivedAt)
+			}
 
-				// Update word count on encountering space or sentence fragment delimiter
-				if unicode.IsSpace(char) || s.fragmentDelimiterSet[char] {
-					s.wordCount++
+			if s.callbacks.OnCharacter != nil {
+				if err := s.callbacks.OnCharacter(char); err != nil {
+					s.err = err
+					return err
 				}
+			}
 
-				// Check conditions to yield first sentence fragment quickly
-				if s.isFirstSentence &&
-					s.buffer.Len() > s.minimumFirstFragmentLength &&
-					(s.quickYieldMode == QuickYieldFirstFragment || s.quickYieldMode == QuickYieldAllFragments) {
+			// Update word count on encountering space or sentence fragment delimiter
+			if unicode.IsSpace(char) || s.fragmentDelimiterSet[char] {
+				s.wordCount++
+			}
 
-					shouldYield := false
+			// Delimiters inside a link do not end a fragment or sentence
+			if s.cleanupOptions.HasFlag(CleanupLinks) && hasURLScheme(s.cleanWord(lastWord(s.buffer.String()))) {
+				continue
+			}
 
-					if s.fragmentDelimiterSet[char] {
-						shouldYield = true
-					}
+			// Check conditions to yield first sentence fragment quickly
+			if s.isFirstSentence &&
+				s.buffer.Len() > s.minimumFirstFragmentLength &&
+				(s.quickYieldMode == QuickYieldFirstFragment || s.quickYieldMode == QuickYieldAllFragments) {
 
-					if shouldYield {
-						yieldText := CleanText(s.buffer.String(), s.cleanupOptions)
-						resultChan <- yieldText
-						s.buffer.Reset()
-						s.wordCount = 0
-						s.isFirstSentence = false
-						continue
-					}
+				shouldYield := false
+
+				if s.fragmentDelimiterSet[char] {
+					shouldYield = true
 				}
 
-				// Continue accumulating characters if buffer is under minimum sentence length
-				if s.buffer.Len() <= s.minimumSentenceLength+s.contextSize {
+				if shouldYield {
+					err := s.yield(s.buffer.String(), true, emit)
+					s.buffer.Reset()
+					s.wordCount = 0
+					s.isFirstSentence = false
+					if err != nil {
+						unreadRest(err)
+						return err
+					}
 					continue
 				}
+			}
 
-				// Update last delimiter position if a new delimiter is found
-				if s.fullDelimiterSet[char] {
-					s.lastDelimiterPosition = s.buffer.Len() - 1
-				}
+			// Continue accumulating characters if buffer is under minimum sentence length
+			if s.buffer.Len() <= s.minimumSentenceLength+s.contextSize {
+				continue
+			}
 
-				// Define context window for checking potential sentence boundaries
-				contextWindowEndPos := s.buffer.Len() - s.contextSize - 1
-				contextWindowStartPos := contextWindowEndPos - s.contextSize
-				if contextWindowStartPos < 0 {
-					contextWindowStartPos = 0
-				}
+			// Update last delimiter position if a new delimiter is found
+			if s.fullDelimiterSet[char] {
+				s.lastDelimiterPosition = s.buffer.Len() - 1
+			}
+
+			// Define context window for checking potential sentence boundaries
+			contextWindowEndPos := s.buffer.Len() - s.contextSize - 1
+			contextWindowStartPos := contextWindowEndPos - s.contextSize
+			if contextWindowStartPos < 0 {
+				contextWindowStartPos = 0
+			}
 
-				// Tokenize sentences from buffer using fragment delimiters
-				sentences := TokenizeSentencesWithDelimiters(s.buffer.String(), s.sentenceFragmentDelimiters)
+			// Tokenize sentences from buffer using fragment delimiters
+			sentences := TokenizeSentencesWithDelimiters(s.buffer.String(), s.sentenceFragmentDelimiters)
 
-				// Combine sentences below minimum_sentence_length with the next sentence(s)
-				combinedSentences := s.combineSentences(sentences)
-				sentences = combinedSentences
+			// Combine sentences below minimum_sentence_length with the next sentence(s)
+			combinedSentences := s.combineSentences(sentences)
+			sentences = combinedSentences
 
-				// Process and yield sentences based on conditions
-				shouldProcess := len(sentences) > 2 ||
-					(s.lastDelimiterPosition >= 0 &&
-						contextWindowStartPos <= s.lastDelimiterPosition &&
-						s.lastDelimiterPosition <= contextWindowEndPos)
+			// Process and yield sentences based on conditions
+			shouldProcess := len(sentences) > 2 ||
+				(s.lastDelimiterPosition >= 0 &&
+					contextWindowStartPos <= s.lastDelimiterPosition &&
+					s.lastDelimiterPosition <= contextWindowEndPos)
 
-				if shouldProcess && len(sentences) > 1 {
-					totalLengthExceptLast := 0
-					for i := 0; i < len(sentences)-1; i++ {
-						totalLengthExceptLast += len(sentences[i])
+			if shouldProcess && len(sentences) > 1 {
+				totalLengthExceptLast := 0
+				for i := 0; i < len(sentences)-1; i++ {
+					totalLengthExceptLast += len(sentences[i])
+				}
+
+				if totalLengthExceptLast >= s.minimumSentenceLength {
+					var err error
+					yielded := 0
+					for ; yielded < len(sentences)-1 && err == nil; yielded++ {
+						err = s.yield(sentences[yielded], false, emit)
+						s.wordCount = 0
 					}
 
-					if totalLengthExceptLast >= s.minimumSentenceLength {
-						for i := 0; i < len(sentences)-1; i++ {
-							yieldText := CleanText(sentences[i], s.cleanupOptions)
-							resultChan <- yieldText
-							s.wordCount = 0
-						}
-
-						if s.quickYieldMode == QuickYieldAllFragments {
-							s.isFirstSentence = true
-						}
-
-						// Handle buffer ending with space
-						endsWithSpace := strings.HasSuffix(s.buffer.String(), " ")
-						s.buffer.Reset()
-						s.buffer.WriteString(sentences[len(sentences)-1])
-						if endsWithSpace {
-							s.buffer.WriteString(" ")
-						}
-
-						// Reset the last delimiter position after yielding
-						s.lastDelimiterPosition = -1
+					if s.quickYieldMode == QuickYieldAllFragments {
+						s.isFirstSentence = true
+					}
+
+					// Handle buffer ending with space
+					endsWithSpace := strings.HasSuffix(s.buffer.String(), " ")
+					s.buffer.Reset()
+					for _, sentence := range sentences[yielded : len(sentences)-1] {
+						// Sentences left over by an interruption stay buffered
+						s.buffer.WriteString(sentence + " ")
+					}
+					s.buffer.WriteString(sentences[len(sentences)-1])
+					if endsWithSpace {
+						s.buffer.WriteString(" ")
+					}
+
+					// Reset the last delimiter position after yielding
+					s.lastDelimiterPosition = -1
+
+					if err != nil {
+						unreadRest(err)
+						return err
 					}
 				}
 			}
 		}
-	}()
+	}
 
-	return resultChan
+	return s.err
 }
 
-// Flush yields remaining buffer as final sentence(s)
-func (s *SentenceSplitter) Flush() <-chan string {
-	resultChan := make(chan string, 10)
+// flush passes the remaining buffer to emit as final sentence(s), stopping
+// at the first error returned by a callback or emit
+func (s *SentenceSplitter) flush(emit func(Sentence) error) error {
+	return s.flushBuffer(emit, false)
+}
 
-	go func() {
-		defer close(resultChan)
+// cut flushes the buffer like flush, but the last sentence is a forced
+// break, as the text was cut off without a sentence boundary
+func (s *SentenceSplitter) cut(emit func(Sentence) error) error {
+	return s.flushBuffer(emit, true)
+}
 
-		if s.buffer.Len() > 0 {
-			sentences := TokenizeSentencesWithDelimiters(s.buffer.String(), s.sentenceFragmentDelimiters)
-			sentenceBuffer := ""
+// flushBuffer implements flush and cut
+func (s *SentenceSplitter) flushBuffer(emit func(Sentence) error, forced bool) error {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
 
-			for _, sentence := range sentences {
-				sentenceBuffer += sentence
-				if len(sentenceBuffer) < s.minimumSentenceLength {
-					sentenceBuffer += " "
-					continue
-				}
+	if s.err == nil && s.closed() {
+		s.err = ErrClosed
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+	if s.interrupting.Load() {
+		return ErrInterrupted
+	}
+	s.adapt()
+
+	if s.cleanupOptions.HasFlag(CleanupLinks) {
+		s.dropTrailingURL()
+	}
+
+	s.observer.OnFlush(s.buffer.String())
 
-				yieldText := CleanText(sentenceBuffer, s.cleanupOptions)
-				resultChan <- yieldText
-				sentenceBuffer = ""
+	var err error
+	if s.buffer.Len() > 0 {
+		sentences := TokenizeSentencesWithDelimiters(s.buffer.String(), s.sentenceFragmentDelimiters)
+		sentenceBuffer := ""
+
+		for i, sentence := range sentences {
+			if s.interrupting.Load() {
+				// The sentences not yet emitted stay buffered
+				s.buffer.Reset()
+				s.buffer.WriteString(strings.TrimSpace(sentenceBuffer + strings.Join(sentences[i:], " ")))
+				return ErrInterrupted
+			}
+
+			sentenceBuffer += sentence
+			if len(sentenceBuffer) < s.minimumSentenceLength {
+				sentenceBuffer += " "
+				continue
 			}
 
-			if sentenceBuffer != "" {
-				yieldText := CleanText(sentenceBuffer, s.cleanupOptions)
-				resultChan <- yieldText
+			if err = s.yield(sentenceBuffer, forced && i == len(sentences)-1, emit); err != nil {
+				if errors.Is(err, ErrInterrupted) {
+					// The sentences not yet emitted stay buffered
+					s.buffer.Reset()
+					s.buffer.WriteString(strings.Join(sentences[i+1:], " "))
+					return err
+				}
+				break
 			}
+			sentenceBuffer = ""
 		}
-	}()
 
-	return resultChan
+		if err == nil && sentenceBuffer != "" {
+			err = s.yield(sentenceBuffer, forced, emit)
+		}
+	}
+
+	// Leave the splitter empty so nothing is yielded twice
+	s.clearBuffer()
+
+	return err
+}
+
+// dropTrailingURL removes a link ending the buffer along with the
+// whitespace before it, as CleanText would
+func (s *SentenceSplitter) dropTrailingURL() {
+	text := s.buffer.String()
+	word := lastWord(text)
+	if !isHTTPURL(s.cleanWord(word)) {
+		return
+	}
+
+	if s.metrics != nil {
+		n := min(utf8.RuneCountInString(word), len(s.arrivals))
+		s.arrivals = s.arrivals[:len(s.arrivals)-n]
+	}
+
+	text = strings.TrimRightFunc(text[:len(text)-len(word)], unicode.IsSpace)
+	s.buffer.Reset()
+	s.buffer.WriteString(text)
+	if s.lastDelimiterPosition >= len(text) {
+		s.lastDelimiterPosition = -1
+	}
+}
+
+// cleanWord removes the emojis of word if they are cleaned up, so links
+// are recognized as CleanText does
+func (s *SentenceSplitter) cleanWord(word string) string {
+	if s.cleanupOptions.HasFlag(CleanupEmojis) {
+		return stripEmojis(word)
+	}
+	return word
+}
+
+// clearBuffer drops the buffered text and the state derived from it
+func (s *SentenceSplitter) clearBuffer() {
+	s.buffer.Reset()
+	s.isFirstSentence = true
+	s.wordCount = 0
+	s.lastDelimiterPosition = -1
+	s.arrivals = s.arrivals[:0]
+}
+
+// yield cleans a sentence taken from the front of the buffer and emits it
+// unless cleanup left nothing to say. Forced sentences were cut without a
+// confirmed sentence boundary.
+func (s *SentenceSplitter) yield(sentence string, forced bool, emit func(Sentence) error) error {
+	text := CleanText(sentence, s.cleanupOptions)
+	if text != strings.TrimSpace(sentence) {
+		s.observer.OnCleanupApplied(sentence, text, s.cleanupOptions)
+	}
+
+	// Observers and timing are only notified once the sentence is delivered
+	var stats SentenceStats
+	timed := false
+	if s.metrics != nil {
+		stats, timed = s.sentenceStats(sentence, text)
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	result := Sentence{Text: text, Index: s.emitted, Fragment: forced}
+	s.emitted++
+
+	callback := s.callbacks.OnSentence
+	if forced && s.callbacks.OnFragment != nil {
+		callback = s.callbacks.OnFragment
+	}
+	if callback != nil {
+		if err := callback(result); err != nil {
+			s.err = err
+			return err
+		}
+	}
+
+	if emit != nil {
+		if err := emit(result); err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				s.cutOff = append(s.cutOff, text)
+			}
+			s.err = err
+			return err
+		}
+	}
+
+	if forced {
+		s.observer.OnForcedBreak(text)
+	}
+	s.observer.OnSentence(text)
+
+	if timed {
+		s.recordSentence(stats)
+	}
+	return nil
 }
 
 // combineSentences combines short sentences with following ones
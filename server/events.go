@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// handleEvents streams the request body through a splitter and sends the
+// sentences back as Server-Sent Events while the body is still arriving. A
+// body of type application/x-ndjson holds Input messages, one per line,
+// and may start with a config message; any other body is plain text.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	config, err := s.requestConfig(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, done, ok := s.startSession(r.Context())
+	if !ok {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer done()
+
+	if limit := s.config.MaxInputBytes; limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	// Respond while the request body is still being read. HTTP/2 always
+	// allows this; for HTTP/1 it has to be enabled.
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+
+	// Unblock a pending body read once the session is aborted
+	stop := context.AfterFunc(ctx, func() { rc.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	source := readerSource(r.Body)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-ndjson" {
+		decoder := json.NewDecoder(r.Body)
+		source, err = s.messageSource(ctx, &config, func(ctx context.Context) (Input, error) {
+			var input Input
+			err := decoder.Decode(&input)
+			return input, err
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	source, sent := s.limitSource(source)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	count := 0
+	for sentence, err := range stream2sentence.SentencesFromSource(ctx, source, config) {
+		if err != nil {
+			writeEvent(w, TypeError, Error{Type: TypeError, Error: err.Error()})
+			rc.Flush()
+			return
+		}
+
+		err = writeEvent(w, TypeSentence, Sentence{
+			Type:     TypeSentence,
+			Text:     sentence.Text,
+			Index:    sentence.Index,
+			Fragment: sentence.Fragment,
+		})
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+		sent()
+		count++
+	}
+
+	writeEvent(w, TypeDone, Done{Type: TypeDone, Sentences: count})
+	rc.Flush()
+}
+
+// writeEvent writes a single server-sent event with a JSON payload
+func writeEvent(w io.Writer, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+	return err
+}
@@ -0,0 +1,17 @@
+module github.com/txt-dot/stream2sentence/server
+
+go 1.24.0
+
+require (
+	github.com/coder/websocket v1.8.15
+	github.com/stretchr/testify v1.8.4
+	github.com/txt-dot/stream2sentence v0.1.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/txt-dot/stream2sentence => ../
@@ -0,0 +1,414 @@
+// Package server exposes sentence splitting over HTTP. Text is streamed in
+// as a chunked POST body or as WebSocket messages, and sentences are
+// streamed back as Server-Sent Events or WebSocket messages carrying JSON
+// metadata. Every connection gets its own SentenceSplitter.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// Message types sent to clients
+const (
+	TypeSentence = "sentence"
+	TypeDone     = "done"
+	TypeError    = "error"
+)
+
+// Sentence is the message sent for every emitted sentence
+type Sentence struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Index    int    `json:"index"`
+	Fragment bool   `json:"fragment"`
+}
+
+// Done is the last message of a successful session
+type Done struct {
+	Type      string `json:"type"`
+	Sentences int    `json:"sentences"`
+}
+
+// Error is the last message of a failed session
+type Error struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// Default limits of a Config
+const (
+	DefaultMaxInputBytes    = 16 << 20
+	DefaultMaxBufferedBytes = 64 << 10
+	DefaultMaxLength        = 1024
+)
+
+// errInputTooLarge ends a session whose input exceeds MaxInputBytes
+var errInputTooLarge = errors.New("input exceeds the size limit")
+
+// Config holds the server options
+type Config struct {
+	// Splitter is the configuration of every connection's splitter. Clients
+	// may override its tuning options with query parameters named like its
+	// JSON fields, e.g. ?context_size=8&quick_yield_mode=none, or pick a
+	// preset with ?preset=name. A config message sent first overrides them
+	// the same way. Callbacks are not used.
+	Splitter stream2sentence.SentenceSplitterConfig
+
+	// MaxInputBytes caps the input of a session, the request body of a
+	// POST or the messages of a WebSocket connection. Zero means
+	// DefaultMaxInputBytes and a negative value no limit.
+	MaxInputBytes int64
+
+	// MaxBufferedBytes caps the text received since the last sentence.
+	// Once more has arrived, the buffered text is flushed as if the client
+	// had sent a flush. Zero means DefaultMaxBufferedBytes and a negative
+	// value no limit.
+	MaxBufferedBytes int
+
+	// MaxLength caps the context_size, minimum_sentence_length and
+	// minimum_first_fragment_length clients may set; larger values are
+	// clamped. Zero means DefaultMaxLength and a negative value no limit.
+	MaxLength int
+
+	// UpstreamErrorPolicy decides whether text buffered when a client's
+	// input breaks off is still sent
+	UpstreamErrorPolicy stream2sentence.UpstreamErrorPolicy
+
+	// OriginPatterns lists the hosts, besides the server's own, from which
+	// browsers may open WebSocket connections, e.g. "*.example.com"
+	OriginPatterns []string
+}
+
+// Server splits text streamed by clients into sentences. Its routes are
+// POST /split for Server-Sent Events, GET /split for WebSocket connections
+// and GET /healthz.
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+
+	// ctx is cancelled to abort all sessions when a graceful shutdown
+	// runs out of time
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	http     *http.Server
+	closing  bool
+	sessions sync.WaitGroup
+}
+
+// New creates a Server from the given configuration
+func New(config Config) *Server {
+	config.Splitter.Callbacks = stream2sentence.Callbacks{}
+	if config.MaxInputBytes == 0 {
+		config.MaxInputBytes = DefaultMaxInputBytes
+	}
+	if config.MaxBufferedBytes == 0 {
+		config.MaxBufferedBytes = DefaultMaxBufferedBytes
+	}
+	if config.MaxLength == 0 {
+		config.MaxLength = DefaultMaxLength
+	}
+
+	s := &Server{config: config, mux: http.NewServeMux()}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.http = &http.Server{Handler: s}
+
+	s.mux.HandleFunc("POST /split", s.handleEvents)
+	s.mux.HandleFunc("GET /split", s.handleWebSocket)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve accepts connections on l until Shutdown is called, in which case it
+// returns http.ErrServerClosed
+func (s *Server) Serve(l net.Listener) error {
+	return s.http.Serve(l)
+}
+
+// ListenAndServe listens on the TCP address addr and calls Serve
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Shutdown stops accepting connections and waits for running sessions to
+// finish. If ctx is done first, the remaining sessions are aborted and
+// Shutdown returns the context's error once they have ended.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	err := s.http.Shutdown(ctx)
+
+	// WebSocket connections are hijacked, so http.Server does not wait
+	// for them
+	done := make(chan struct{})
+	go func() {
+		s.sessions.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.cancel()
+	<-done
+	return err
+}
+
+// startSession registers a session and returns its context, which is done
+// when the request ends or the server aborts all sessions. It returns false
+// if the server is shutting down.
+func (s *Server) startSession(ctx context.Context) (context.Context, func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return nil, nil, false
+	}
+	s.sessions.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		s.sessions.Done()
+	}, true
+}
+
+// intFields lists the configuration fields that take integer values
+var intFields = map[string]bool{
+	"context_size":                  true,
+	"minimum_sentence_length":       true,
+	"minimum_first_fragment_length": true,
+}
+
+// requestConfig applies the overrides given in the query to the server's
+// splitter configuration
+func (s *Server) requestConfig(query url.Values) (stream2sentence.GenerateSentencesConfig, error) {
+	overrides := make(map[string]any)
+	for key, values := range query {
+		value := values[len(values)-1]
+		if intFields[key] {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return stream2sentence.GenerateSentencesConfig{}, fmt.Errorf("invalid %s: %q is not an integer", key, value)
+			}
+			overrides[key] = n
+		} else {
+			overrides[key] = value
+		}
+	}
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return stream2sentence.GenerateSentencesConfig{}, err
+	}
+
+	config, err := s.overrideConfig(s.config.Splitter, data)
+	if err != nil {
+		return stream2sentence.GenerateSentencesConfig{}, fmt.Errorf("invalid query: %w", err)
+	}
+
+	return stream2sentence.GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+		UpstreamErrorPolicy:    s.config.UpstreamErrorPolicy,
+	}, nil
+}
+
+// overrideConfig applies the overrides in the JSON object data to config. A
+// "preset" field picks a preset, the other fields are named like the JSON
+// fields of the configuration. Lengths are clamped to MaxLength.
+func (s *Server) overrideConfig(config stream2sentence.SentenceSplitterConfig, data []byte) (stream2sentence.SentenceSplitterConfig, error) {
+	var overrides map[string]json.RawMessage
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return config, err
+	}
+
+	if raw, ok := overrides["preset"]; ok {
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return config, fmt.Errorf("invalid preset: %w", err)
+		}
+
+		preset, err := stream2sentence.Preset(name)
+		if err != nil {
+			return config, err
+		}
+		config = config.WithTuning(preset)
+		delete(overrides, "preset")
+	}
+
+	if len(overrides) > 0 {
+		data, err := json.Marshal(overrides)
+		if err != nil {
+			return config, err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return config, err
+		}
+	}
+
+	if limit := s.config.MaxLength; limit > 0 {
+		lengths := map[string]*int{
+			"context_size":                  &config.ContextSize,
+			"minimum_sentence_length":       &config.MinimumSentenceLength,
+			"minimum_first_fragment_length": &config.MinimumFirstFragmentLength,
+		}
+		for field, length := range lengths {
+			if _, ok := overrides[field]; ok {
+				*length = min(*length, limit)
+			}
+		}
+	}
+
+	return config, config.Validate()
+}
+
+// messageSource returns a Source for the Input messages returned by read.
+// A config message sent first is applied to config; later ones are
+// rejected. The error reports an invalid config message.
+func (s *Server) messageSource(ctx context.Context, config *stream2sentence.GenerateSentencesConfig, read func(context.Context) (Input, error)) (stream2sentence.Source, error) {
+	first, firstErr := read(ctx)
+	pending := true
+
+	if firstErr == nil && first.Type == TypeConfig {
+		splitter, err := s.overrideConfig(config.SentenceSplitterConfig, first.Config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		config.SentenceSplitterConfig = splitter
+		pending = false
+	}
+
+	return stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		input, err := first, firstErr
+		if !pending {
+			input, err = read(ctx)
+		}
+		pending = false
+
+		if err != nil {
+			return "", err
+		}
+
+		switch input.Type {
+		case TypeChunk:
+			return input.Text, nil
+		case TypeFlush:
+			return "", stream2sentence.ErrBoundary
+		case TypeEnd:
+			return "", io.EOF
+		case TypeConfig:
+			return "", errors.New("config is only accepted as the first message")
+		default:
+			return "", fmt.Errorf("unknown message type %q", input.Type)
+		}
+	}), nil
+}
+
+// limitSource ends the input of source with an error once it exceeds
+// MaxInputBytes, and forces a sentence boundary once MaxBufferedBytes have
+// arrived since the last sentence. The returned function must be called for
+// every sentence.
+func (s *Server) limitSource(source stream2sentence.Source) (stream2sentence.Source, func()) {
+	var input int64
+	buffered := 0
+
+	limited := stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		if limit := s.config.MaxBufferedBytes; limit > 0 && buffered > limit {
+			buffered = 0
+			return "", stream2sentence.ErrBoundary
+		}
+
+		chunk, err := source.Next(ctx)
+		input += int64(len(chunk))
+		buffered += len(chunk)
+
+		if limit := s.config.MaxInputBytes; limit > 0 && input > limit {
+			return "", errInputTooLarge
+		}
+		if errors.Is(err, stream2sentence.ErrBoundary) {
+			buffered = 0
+		}
+		return chunk, err
+	})
+
+	return limited, func() { buffered = 0 }
+}
+
+// readerSource returns a Source reading chunks from r. Chunks end on rune
+// boundaries, so multi-byte characters split across reads stay intact.
+func readerSource(r io.Reader) stream2sentence.Source {
+	buf := make([]byte, 4096)
+	var pending []byte
+
+	return stream2sentence.SourceFunc(func(ctx context.Context) (string, error) {
+		for {
+			n, err := r.Read(buf)
+			pending = append(pending, buf[:n]...)
+
+			if err != nil {
+				if len(pending) > 0 && errors.Is(err, io.EOF) {
+					chunk := string(pending)
+					pending = nil
+					return chunk, nil
+				}
+				return "", err
+			}
+
+			if end := completeRunes(pending); end > 0 {
+				chunk := string(pending[:end])
+				pending = append(pending[:0], pending[end:]...)
+				return chunk, nil
+			}
+		}
+	})
+}
+
+// completeRunes returns the length of the longest prefix of p that does not
+// end in an incomplete UTF-8 sequence
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
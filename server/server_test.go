@@ -0,0 +1,363 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/txt-dot/stream2sentence"
+	"github.com/txt-dot/stream2sentence/sse"
+)
+
+// message is any message sent by the server
+type message struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Index     int    `json:"index"`
+	Fragment  bool   `json:"fragment"`
+	Sentences int    `json:"sentences"`
+	Error     string `json:"error"`
+}
+
+// nextEvent reads the next server-sent event and decodes its payload
+func nextEvent(t *testing.T, events *sse.Reader) message {
+	t.Helper()
+
+	event, err := events.Next()
+	require.NoError(t, err)
+
+	var m message
+	require.NoError(t, json.Unmarshal([]byte(event.Data), &m))
+	assert.Equal(t, event.Type, m.Type)
+	return m
+}
+
+// dial opens a WebSocket session to the test server
+func dial(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(server.URL, "http")+"/split"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+// === Server-Sent Events Tests ===
+
+func TestEventsStreaming(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	body, input := io.Pipe()
+	defer input.Close()
+
+	response, err := http.Post(server.URL+"/split", "text/plain", body)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	events := sse.NewReader(response.Body)
+
+	// The first sentence arrives while the request body is still open
+	_, err = io.WriteString(input, "This is the first sentence. And here")
+	require.NoError(t, err)
+	assert.Equal(t, message{Type: TypeSentence, Text: "This is the first sentence.", Fragment: true}, nextEvent(t, events))
+
+	_, err = io.WriteString(input, " comes the second one.")
+	require.NoError(t, err)
+	require.NoError(t, input.Close())
+
+	assert.Equal(t, message{Type: TypeSentence, Text: "And here comes the second one.", Index: 1}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeDone, Sentences: 2}, nextEvent(t, events))
+}
+
+func TestEventsOverrides(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	response, err := http.Post(server.URL+"/split?preset=narration&minimum_sentence_length=5&cleanup_options=none", "text/plain",
+		strings.NewReader("Hi, there. How are you?"))
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	events := sse.NewReader(response.Body)
+	assert.Equal(t, message{Type: TypeSentence, Text: "Hi, there."}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeSentence, Text: "How are you?", Index: 1}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeDone, Sentences: 2}, nextEvent(t, events))
+}
+
+func TestEventsInvalidOverrides(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	for _, query := range []string{
+		"?context_size=large",
+		"?quick_yield_mode=sometimes",
+		"?unknown_option=1",
+		"?preset=fastest",
+		"?context_size=-1",
+	} {
+		response, err := http.Post(server.URL+"/split"+query, "text/plain", strings.NewReader("Text."))
+		require.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, query)
+	}
+}
+
+func TestEventsBodyConfig(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	body := `{"type":"config","config":{"preset":"narration","minimum_sentence_length":5}}
+{"type":"chunk","text":"Hi, there. How"}
+{"type":"flush"}
+{"type":"chunk","text":"are you?"}
+`
+	response, err := http.Post(server.URL+"/split?cleanup_options=none", "application/x-ndjson", strings.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	events := sse.NewReader(response.Body)
+	assert.Equal(t, message{Type: TypeSentence, Text: "Hi, there."}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeSentence, Text: "How", Index: 1}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeSentence, Text: "are you?", Index: 2}, nextEvent(t, events))
+	assert.Equal(t, message{Type: TypeDone, Sentences: 3}, nextEvent(t, events))
+
+	response, err = http.Post(server.URL+"/split", "application/x-ndjson",
+		strings.NewReader(`{"type":"config","config":{"context_size":-1}}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestEventsLimits(t *testing.T) {
+	server := httptest.NewServer(New(Config{
+		Splitter:         stream2sentence.DefaultConfig(),
+		MaxInputBytes:    64,
+		MaxBufferedBytes: 16,
+	}))
+	defer server.Close()
+
+	// Text without a sentence boundary is flushed once it exceeds the
+	// buffer cap, while the body is still open
+	body, input := io.Pipe()
+	defer input.Close()
+
+	response, err := http.Post(server.URL+"/split", "text/plain", body)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	_, err = io.WriteString(input, "no boundary in sight ")
+	require.NoError(t, err)
+	events := sse.NewReader(response.Body)
+	assert.Equal(t, message{Type: TypeSentence, Text: "no boundary in sight"}, nextEvent(t, events))
+	require.NoError(t, input.Close())
+
+	// Input beyond the size limit ends the session with an error
+	response, err = http.Post(server.URL+"/split", "text/plain", strings.NewReader(strings.Repeat("Word. ", 20)))
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	events = sse.NewReader(response.Body)
+	var last message
+	for last.Type != TypeError && last.Type != TypeDone {
+		last = nextEvent(t, events)
+	}
+	assert.Equal(t, TypeError, last.Type)
+}
+
+func TestOverridesClamped(t *testing.T) {
+	s := New(Config{Splitter: stream2sentence.DefaultConfig(), MaxLength: 100})
+
+	config, err := s.requestConfig(url.Values{"context_size": {"100000"}, "minimum_sentence_length": {"50"}})
+	require.NoError(t, err)
+	assert.Equal(t, 100, config.ContextSize)
+	assert.Equal(t, 50, config.MinimumSentenceLength)
+	assert.Equal(t, stream2sentence.DefaultConfig().MinimumFirstFragmentLength, config.MinimumFirstFragmentLength)
+}
+
+// === WebSocket Tests ===
+
+func TestWebSocket(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	ctx := context.Background()
+	conn := dial(t, server, "?quick_yield_mode=none")
+
+	var m message
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "Let me think about"}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeFlush}))
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeSentence, Text: "Let me think about"}, m)
+
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "The answer is simple. "}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "It is forty two"}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeEnd}))
+
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeSentence, Text: "The answer is simple.", Index: 1}, m)
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeSentence, Text: "It is forty two", Index: 2}, m)
+	m = message{}
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeDone, Sentences: 3}, m)
+
+	_, _, err := conn.Read(ctx)
+	assert.Equal(t, websocket.StatusNormalClosure, websocket.CloseStatus(err))
+}
+
+func TestWebSocketInvalidInput(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	ctx := context.Background()
+	conn := dial(t, server, "")
+
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: "shout", Text: "Hello"}))
+
+	var m message
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, TypeError, m.Type)
+	assert.Contains(t, m.Error, `unknown message type "shout"`)
+}
+
+func TestWebSocketConfigMessage(t *testing.T) {
+	server := httptest.NewServer(New(Config{Splitter: stream2sentence.DefaultConfig()}))
+	defer server.Close()
+
+	ctx := context.Background()
+	conn := dial(t, server, "")
+
+	var m message
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeConfig, Config: json.RawMessage(`{"quick_yield_mode":"none"}`)}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "Let me check. The"}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeEnd}))
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeSentence, Text: "Let me check."}, m)
+
+	// A config message is only accepted first
+	conn = dial(t, server, "")
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "Hello"}))
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeConfig, Config: json.RawMessage(`{}`)}))
+	m = message{}
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, TypeError, m.Type)
+	assert.Contains(t, m.Error, "only accepted as the first message")
+}
+
+// === Shutdown Tests ===
+
+// startServer serves s on a local listener and returns its base URL
+func startServer(t *testing.T, s *Server) (string, <-chan error) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+	return "http://" + l.Addr().String(), served
+}
+
+func TestShutdownWaitsForSessions(t *testing.T) {
+	s := New(Config{Splitter: stream2sentence.DefaultConfig()})
+	url, served := startServer(t, s)
+
+	ctx := context.Background()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(url, "http")+"/split", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeChunk, Text: "Still talking"}))
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- s.Shutdown(ctx) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown returned %v with a session running", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The running session completes normally
+	require.NoError(t, wsjson.Write(ctx, conn, Input{Type: TypeEnd}))
+	var m message
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, message{Type: TypeSentence, Text: "Still talking"}, m)
+	require.NoError(t, wsjson.Read(ctx, conn, &m))
+	assert.Equal(t, TypeDone, m.Type)
+
+	// Complete the closing handshake
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusNormalClosure, websocket.CloseStatus(err))
+
+	require.NoError(t, <-shutdown)
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+}
+
+func TestShutdownAbortsSessions(t *testing.T) {
+	s := New(Config{Splitter: stream2sentence.DefaultConfig()})
+	url, _ := startServer(t, s)
+
+	body, input := io.Pipe()
+	defer input.Close()
+
+	response, err := http.Post(url+"/split", "text/plain", body)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = s.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The aborted stream ends without a done event
+	data, _ := io.ReadAll(response.Body)
+	assert.NotContains(t, string(data), TypeDone)
+}
+
+func TestShutdownRejectsNewSessions(t *testing.T) {
+	s := New(Config{Splitter: stream2sentence.DefaultConfig()})
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/split", strings.NewReader("Text.")))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+// === Reader Source Tests ===
+
+func TestReaderSource(t *testing.T) {
+	text := "Grüße aus Köln 😀. 你好。"
+	source := readerSource(iotest.OneByteReader(strings.NewReader(text)))
+
+	var chunks []string
+	for {
+		chunk, err := source.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.True(t, strings.ToValidUTF8(chunk, "�") == chunk, "chunk %q splits a rune", chunk)
+		chunks = append(chunks, chunk)
+	}
+
+	assert.Equal(t, text, strings.Join(chunks, ""))
+	assert.Len(t, chunks, len([]rune(text)))
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/txt-dot/stream2sentence"
+)
+
+// Input message types sent by WebSocket clients
+const (
+	// TypeChunk carries a text chunk in Text
+	TypeChunk = "chunk"
+
+	// TypeFlush forces a sentence boundary, emitting the buffered text
+	TypeFlush = "flush"
+
+	// TypeEnd ends the input. The remaining text is flushed, a Done message
+	// is sent and the server closes the connection.
+	TypeEnd = "end"
+
+	// TypeConfig overrides the splitter configuration with the JSON object
+	// in Config, like the query parameters. It is only accepted as the
+	// first message.
+	TypeConfig = "config"
+)
+
+// Input is a message sent by a WebSocket client or, one per line, in an
+// application/x-ndjson request body
+type Input struct {
+	Type   string          `json:"type"`
+	Text   string          `json:"text,omitempty"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// handleWebSocket splits the text chunks of a WebSocket session, sending
+// every sentence as a message as soon as it is complete
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	config, err := s.requestConfig(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, done, ok := s.startSession(r.Context())
+	if !ok {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer done()
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: s.config.OriginPatterns})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	source, err := s.messageSource(ctx, &config, func(ctx context.Context) (Input, error) {
+		var input Input
+		err := wsjson.Read(ctx, conn, &input)
+		return input, err
+	})
+	if err != nil {
+		wsjson.Write(ctx, conn, Error{Type: TypeError, Error: err.Error()})
+		conn.Close(websocket.StatusPolicyViolation, "invalid config")
+		return
+	}
+	source, sent := s.limitSource(source)
+
+	count := 0
+	for sentence, err := range stream2sentence.SentencesFromSource(ctx, source, config) {
+		if err != nil {
+			if websocket.CloseStatus(err) != -1 || errors.Is(err, context.Canceled) {
+				// The client is gone or the server is aborting
+				conn.Close(websocket.StatusGoingAway, "")
+				return
+			}
+
+			wsjson.Write(ctx, conn, Error{Type: TypeError, Error: err.Error()})
+			conn.Close(websocket.StatusPolicyViolation, "invalid input")
+			return
+		}
+
+		err = wsjson.Write(ctx, conn, Sentence{
+			Type:     TypeSentence,
+			Text:     sentence.Text,
+			Index:    sentence.Index,
+			Fragment: sentence.Fragment,
+		})
+		if err != nil {
+			return
+		}
+		sent()
+		count++
+	}
+
+	if err := wsjson.Write(ctx, conn, Done{Type: TypeDone, Sentences: count}); err != nil {
+		return
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+}
@@ -0,0 +1,100 @@
+package stream2sentence
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// snapshotVersion is the version of the snapshot format written by Snapshot
+const snapshotVersion = 1
+
+// splitterSnapshot is the serialized state of a SentenceSplitter
+type splitterSnapshot struct {
+	Version               int                    `json:"version"`
+	Config                SentenceSplitterConfig `json:"config"`
+	Buffer                string                 `json:"buffer"`
+	Pending               []string               `json:"pending,omitempty"`
+	IsFirstSentence       bool                   `json:"is_first_sentence"`
+	WordCount             int                    `json:"word_count"`
+	LastDelimiterPosition int                    `json:"last_delimiter_position"`
+	Emitted               int                    `json:"emitted"`
+}
+
+// Snapshot serializes the splitter's configuration, buffered text, pending
+// input and internal state, so a session can be resumed with Restore in
+// another process without losing or repeating text. Metrics, Observer,
+// Callbacks, Output and Adaptive are not part of the snapshot. Like the
+// other consuming methods, Snapshot must not run concurrently with
+// processing.
+func (s *SentenceSplitter) Snapshot() ([]byte, error) {
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	snapshot := splitterSnapshot{
+		Version:               snapshotVersion,
+		Config:                s.tuningConfig(),
+		Buffer:                s.buffer.String(),
+		IsFirstSentence:       s.isFirstSentence,
+		WordCount:             s.wordCount,
+		LastDelimiterPosition: s.lastDelimiterPosition,
+		Emitted:               s.emitted,
+	}
+
+	s.inputMu.Lock()
+	for element := s.inputBuffer.Front(); element != nil; element = element.Next() {
+		snapshot.Pending = append(snapshot.Pending, element.Value.(inputChunk).text)
+	}
+	s.inputMu.Unlock()
+
+	return json.Marshal(snapshot)
+}
+
+// Restore replaces the splitter's configuration and state with a snapshot
+// taken by Snapshot. The configuration in the snapshot is validated before
+// anything is applied. The splitter keeps its own Metrics, Observer,
+// Callbacks, Output and Adaptive options. An error that stopped processing
+// is cleared, like Reset does. Latency metrics are not tracked for text
+// restored into the buffer.
+func (s *SentenceSplitter) Restore(data []byte) error {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("stream2sentence: invalid snapshot: %w", err)
+	}
+
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("stream2sentence: unsupported snapshot version %d", header.Version)
+	}
+
+	var snapshot splitterSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("stream2sentence: invalid snapshot: %w", err)
+	}
+	if err := snapshot.Config.Validate(); err != nil {
+		return fmt.Errorf("stream2sentence: invalid snapshot: %w", err)
+	}
+
+	s.processMu.Lock()
+	defer s.processMu.Unlock()
+
+	s.applyConfig(snapshot.Config)
+
+	s.buffer.Reset()
+	s.buffer.WriteString(snapshot.Buffer)
+	s.isFirstSentence = snapshot.IsFirstSentence
+	s.wordCount = snapshot.WordCount
+	s.lastDelimiterPosition = snapshot.LastDelimiterPosition
+	s.emitted = snapshot.Emitted
+	s.arrivals = s.arrivals[:0]
+	s.err = nil
+
+	s.inputMu.Lock()
+	s.inputBuffer.Init()
+	for _, chunk := range snapshot.Pending {
+		s.inputBuffer.PushBack(inputChunk{text: chunk})
+	}
+	s.inputMu.Unlock()
+
+	return nil
+}
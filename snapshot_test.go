@@ -0,0 +1,103 @@
+package stream2sentence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain processes pending input and returns the emitted sentences
+func drain(splitter *SentenceSplitter, flush bool) []string {
+	var sentences []string
+	for sentence := range splitter.Stream() {
+		sentences = append(sentences, sentence)
+	}
+	if flush {
+		for sentence := range splitter.Flush() {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	text := "Hello there, this is the first sentence. Here is a second one that gets interrupted. And a final one."
+	chunks := splitRunes(text, 7)
+
+	config := DefaultConfig()
+	config.MinimumSentenceLength = 15
+
+	// Uninterrupted reference run
+	reference := NewSentenceSplitter(config)
+	for _, chunk := range chunks {
+		reference.Add(chunk)
+	}
+	expected := drain(reference, true)
+
+	// Interrupted run, migrated mid-sentence with input still pending
+	original := NewSentenceSplitter(config)
+	for _, chunk := range chunks[:8] {
+		original.Add(chunk)
+	}
+	sentences := drain(original, false)
+	for _, chunk := range chunks[8:10] {
+		original.Add(chunk)
+	}
+
+	data, err := original.Snapshot()
+	require.NoError(t, err)
+
+	var indexes []int
+	resumed := NewSentenceSplitter(DefaultConfig())
+	resumed.callbacks.OnSentence = func(sentence Sentence) error {
+		indexes = append(indexes, sentence.Index)
+		return nil
+	}
+	require.NoError(t, resumed.Restore(data))
+	assert.Equal(t, config.MinimumSentenceLength, resumed.minimumSentenceLength)
+
+	for _, chunk := range chunks[10:] {
+		resumed.Add(chunk)
+	}
+	sentences = append(sentences, drain(resumed, true)...)
+
+	assert.Equal(t, expected, sentences)
+	assert.NotEmpty(t, indexes)
+	assert.Equal(t, len(sentences)-1, indexes[len(indexes)-1])
+}
+
+func TestRestoreErrors(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+
+	assert.ErrorContains(t, splitter.Restore([]byte("not json")), "invalid snapshot")
+	assert.ErrorContains(t, splitter.Restore([]byte(`{"version": 99}`)), "unsupported snapshot version 99")
+
+	// A tampered configuration is rejected and leaves the splitter unchanged
+	tampered := `{"version":1,"config":{"context_size":-1,"sentence_fragment_delimiters":"",` +
+		`"full_sentence_delimiters":"."},"buffer":"Kept","is_first_sentence":true}`
+	err := splitter.Restore([]byte(tampered))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "invalid snapshot")
+	assert.Equal(t, DefaultConfig().ContextSize, splitter.tuningConfig().ContextSize)
+	assert.Empty(t, splitter.buffer.String())
+}
+
+func TestRestoreClearsError(t *testing.T) {
+	source := NewSentenceSplitter(DefaultConfig())
+	source.Add("Half a sentence")
+	data, err := source.Snapshot()
+	require.NoError(t, err)
+
+	// A splitter stopped by a callback error works again once restored
+	config := DefaultConfig()
+	failure := errors.New("speaker unavailable")
+	config.Callbacks.OnSentence = func(Sentence) error { return failure }
+	splitter := NewSentenceSplitter(config)
+	assert.ErrorIs(t, splitter.Feed("First sentence here. Second one follows."), failure)
+	require.ErrorIs(t, splitter.Err(), failure)
+
+	require.NoError(t, splitter.Restore(data))
+	assert.NoError(t, splitter.Err())
+}
@@ -0,0 +1,261 @@
+package stream2sentence
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"sync"
+)
+
+// ErrUpstream matches every UpstreamError via errors.Is
+var ErrUpstream = errors.New("stream2sentence: upstream error")
+
+// UpstreamError reports that the source of text chunks failed
+type UpstreamError struct {
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	return "stream2sentence: upstream error: " + e.Err.Error()
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrUpstream
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrUpstream
+}
+
+// UpstreamErrorPolicy decides what happens to buffered text when the source
+// of text chunks fails
+type UpstreamErrorPolicy int
+
+const (
+	// DiscardPartial drops the buffered text, so a sentence cut off by the
+	// failure is never emitted
+	DiscardPartial UpstreamErrorPolicy = iota
+
+	// FlushPartial emits the buffered text as final sentences before the error
+	FlushPartial
+)
+
+// ErrBoundary may be returned by Source.Next, also wrapped, to force a
+// sentence boundary, e.g. between separate blocks of a model response. The
+// buffered text, including a chunk returned along with ErrBoundary, is
+// emitted as if the stream had ended and reading goes on; ErrBoundary itself
+// is never reported to the consumer.
+var ErrBoundary = errors.New("stream2sentence: sentence boundary")
+
+// Source produces text chunks. Next returns io.EOF once the stream has ended,
+// ErrBoundary to end the current sentence and any other error if the stream
+// failed.
+type Source interface {
+	Next(ctx context.Context) (string, error)
+}
+
+// SourceFunc adapts an ordinary function to the Source interface
+type SourceFunc func(ctx context.Context) (string, error)
+
+// Next calls f(ctx)
+func (f SourceFunc) Next(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// SourceChannel reads a Source into a channel of chunks for GenerateSentences
+// and keeps the error that closed the channel. The zero value is ready to
+// use. A channel cannot carry sentence boundaries, so Sources returning
+// ErrBoundary should be read with SentencesFromSource instead.
+type SourceChannel struct {
+	mu  sync.Mutex
+	err error
+}
+
+// Chunks returns a channel of the chunks of src. The channel is closed at
+// the end of the stream, on error or when ctx is done; Err then reports why.
+func (c *SourceChannel) Chunks(ctx context.Context, src Source) <-chan string {
+	chunks := make(chan string)
+
+	go func() {
+		defer close(chunks)
+
+		for {
+			chunk, err := src.Next(ctx)
+			if err != nil {
+				c.setErr(err)
+				return
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				c.setErr(ctx.Err())
+				return
+			}
+		}
+	}()
+
+	return chunks
+}
+
+// Err returns the error that closed the channel returned by Chunks, or nil
+// if the stream completed normally
+func (c *SourceChannel) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// setErr records the error that ended Chunks
+func (c *SourceChannel) setErr(err error) {
+	if errors.Is(err, io.EOF) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// ChannelSource returns a Source reading chunks from a channel until it is closed
+func ChannelSource(generator <-chan string) Source {
+	return SourceFunc(func(ctx context.Context) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case chunk, ok := <-generator:
+			if !ok {
+				return "", io.EOF
+			}
+			return chunk, nil
+		}
+	})
+}
+
+// SentencesFromSource returns an iterator over the sentences generated from
+// the chunks of src. A failure of src ends the sequence with an UpstreamError
+// after the buffered text was handled according to config.UpstreamErrorPolicy.
+// Cancellation of ctx and callback failures end it with their own errors.
+func SentencesFromSource(ctx context.Context, src Source, config GenerateSentencesConfig) iter.Seq2[Sentence, error] {
+	return func(yield func(Sentence, error) bool) {
+		next := func() (string, error) {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+
+			chunk, err := src.Next(ctx)
+			if err == nil || err == io.EOF {
+				return chunk, err
+			}
+			if errors.Is(err, ErrBoundary) {
+				return chunk, ErrBoundary
+			}
+
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", &UpstreamError{Err: err}
+		}
+
+		generateSentences(next, config, yield)
+	}
+}
+
+// SentencesFromSeq returns an iterator over the sentences generated from the
+// chunks of seq. ErrBoundary yielded by seq forces a sentence boundary. Any
+// other error ends the sequence with an UpstreamError after the buffered text
+// was handled according to config.UpstreamErrorPolicy.
+func SentencesFromSeq(seq iter.Seq2[string, error], config GenerateSentencesConfig) iter.Seq2[Sentence, error] {
+	return func(yield func(Sentence, error) bool) {
+		pull, stop := iter.Pull2(seq)
+		defer stop()
+
+		next := func() (string, error) {
+			chunk, err, ok := pull()
+			if !ok {
+				return "", io.EOF
+			}
+
+			if errors.Is(err, ErrBoundary) {
+				return chunk, ErrBoundary
+			}
+			if err != nil {
+				return "", &UpstreamError{Err: err}
+			}
+			return chunk, nil
+		}
+
+		generateSentences(next, config, yield)
+	}
+}
+
+// generateSentences splits the chunks returned by next until it returns
+// io.EOF or another error other than ErrBoundary, passing sentences and the
+// final error to yield
+func generateSentences(next func() (string, error), config GenerateSentencesConfig, yield func(Sentence, error) bool) {
+	var (
+		splitter = NewSentenceSplitter(config.SentenceSplitterConfig)
+		pending  []Sentence
+	)
+
+	collect := func(sentence Sentence) error {
+		pending = append(pending, sentence)
+		return nil
+	}
+
+	// emit passes the collected sentences and err, if any, to yield and
+	// reports whether generation should go on
+	emit := func(err error) bool {
+		for _, sentence := range pending {
+			if !yield(sentence, nil) {
+				return false
+			}
+		}
+		pending = pending[:0]
+
+		if err != nil {
+			yield(Sentence{}, err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		chunk, err := next()
+		if err == io.EOF {
+			emit(splitter.flush(collect))
+			return
+		}
+
+		if errors.Is(err, ErrBoundary) {
+			// A chunk returned with the boundary ends before it
+			if chunk != "" {
+				splitter.Add(chunk)
+				if !emit(splitter.process(collect)) {
+					return
+				}
+			}
+			if !emit(splitter.flush(collect)) {
+				return
+			}
+			continue
+		}
+
+		if err != nil {
+			if errors.Is(err, ErrUpstream) && config.UpstreamErrorPolicy == FlushPartial {
+				if flushErr := splitter.flush(collect); flushErr != nil {
+					err = flushErr
+				}
+			}
+			emit(err)
+			return
+		}
+
+		splitter.Add(chunk)
+		if !emit(splitter.process(collect)) {
+			return
+		}
+	}
+}
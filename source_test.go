@@ -0,0 +1,208 @@
+package stream2sentence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceSource returns a Source yielding chunks followed by err
+func sliceSource(chunks []string, err error) Source {
+	return SourceFunc(func(context.Context) (string, error) {
+		if len(chunks) == 0 {
+			return "", err
+		}
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk, nil
+	})
+}
+
+// sliceSeq returns a sequence yielding chunks followed by err, if any
+func sliceSeq(chunks []string, err error) func(func(string, error) bool) {
+	return func(yield func(string, error) bool) {
+		for _, chunk := range chunks {
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield("", err)
+		}
+	}
+}
+
+func collectTexts(t *testing.T, seq func(func(Sentence, error) bool)) ([]string, error) {
+	t.Helper()
+
+	var texts []string
+	for sentence, err := range seq {
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, sentence.Text)
+	}
+	return texts, nil
+}
+
+var sourceChunks = []string{"This is the first sentence. ", "And this one is cut off mid"}
+
+func TestSentencesFromSource(t *testing.T) {
+	config := GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()}
+
+	texts, err := collectTexts(t, SentencesFromSource(context.Background(), sliceSource(sourceChunks, io.EOF), config))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"This is the first sentence.", "And this one is cut off mid"}, texts)
+}
+
+func TestSentencesFromSourceUpstreamError(t *testing.T) {
+	errBroken := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		policy   UpstreamErrorPolicy
+		expected []string
+	}{
+		{
+			name:     "Discard partial",
+			policy:   DiscardPartial,
+			expected: []string{"This is the first sentence."},
+		},
+		{
+			name:     "Flush partial",
+			policy:   FlushPartial,
+			expected: []string{"This is the first sentence.", "And this one is cut off mid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GenerateSentencesConfig{
+				SentenceSplitterConfig: DefaultConfig(),
+				UpstreamErrorPolicy:    tt.policy,
+			}
+
+			texts, err := collectTexts(t, SentencesFromSource(context.Background(), sliceSource(sourceChunks, errBroken), config))
+			assert.Equal(t, tt.expected, texts)
+			assert.ErrorIs(t, err, ErrUpstream)
+			assert.ErrorIs(t, err, errBroken)
+
+			var upstream *UpstreamError
+			require.ErrorAs(t, err, &upstream)
+			assert.Equal(t, errBroken, upstream.Err)
+		})
+	}
+}
+
+func TestSentencesFromSourceCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	generator := make(chan string, 1)
+	generator <- "Some text that never ends"
+
+	config := GenerateSentencesConfig{
+		SentenceSplitterConfig: DefaultConfig(),
+		UpstreamErrorPolicy:    FlushPartial,
+	}
+
+	go cancel()
+	texts, err := collectTexts(t, SentencesFromSource(ctx, ChannelSource(generator), config))
+	assert.Empty(t, texts)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrUpstream)
+}
+
+func TestSentencesFromSeq(t *testing.T) {
+	config := GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()}
+
+	texts, err := collectTexts(t, SentencesFromSeq(sliceSeq(sourceChunks, nil), config))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"This is the first sentence.", "And this one is cut off mid"}, texts)
+
+	errBroken := errors.New("stream died")
+	texts, err = collectTexts(t, SentencesFromSeq(sliceSeq(sourceChunks, errBroken), config))
+	assert.Equal(t, []string{"This is the first sentence."}, texts)
+	assert.ErrorIs(t, err, ErrUpstream)
+	assert.ErrorIs(t, err, errBroken)
+}
+
+func TestSentencesFromSourceBoundary(t *testing.T) {
+	results := []struct {
+		chunk string
+		err   error
+	}{
+		{"Let me think about", nil},
+		{"", ErrBoundary},
+		{"The answer is ", nil},
+		{"forty", nil},
+		{" two", ErrBoundary},
+		{"", fmt.Errorf("end of block 2: %w", ErrBoundary)},
+		{"Anything else?", nil},
+		{"", io.EOF},
+	}
+
+	src := SourceFunc(func(context.Context) (string, error) {
+		result := results[0]
+		results = results[1:]
+		return result.chunk, result.err
+	})
+
+	config := GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()}
+	texts, err := collectTexts(t, SentencesFromSource(context.Background(), src, config))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Let me think about", "The answer is forty two", "Anything else?"}, texts)
+
+	seq := func(yield func(string, error) bool) {
+		_ = yield("One", nil) && yield(" block", fmt.Errorf("end of block: %w", ErrBoundary)) && yield("and another", nil)
+	}
+	texts, err = collectTexts(t, SentencesFromSeq(seq, config))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"One block", "and another"}, texts)
+}
+
+func TestSentencesFromSeqEarlyExit(t *testing.T) {
+	config := GenerateSentencesConfig{SentenceSplitterConfig: DefaultConfig()}
+
+	var pulled int
+	seq := func(yield func(string, error) bool) {
+		for {
+			pulled++
+			if !yield("Another sentence is here. ", nil) {
+				return
+			}
+		}
+	}
+
+	var texts []string
+	for sentence, err := range SentencesFromSeq(seq, config) {
+		require.NoError(t, err)
+		texts = append(texts, sentence.Text)
+		if len(texts) == 3 {
+			break
+		}
+	}
+
+	assert.Len(t, texts, 3)
+	assert.Less(t, pulled, 10)
+}
+
+func TestSourceChannel(t *testing.T) {
+	var channel SourceChannel
+	var chunks []string
+	for chunk := range channel.Chunks(context.Background(), sliceSource([]string{"Hello ", "world."}, io.EOF)) {
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, []string{"Hello ", "world."}, chunks)
+	require.NoError(t, channel.Err())
+
+	failure := errors.New("connection reset")
+	for range channel.Chunks(context.Background(), sliceSource([]string{"Hello"}, failure)) {
+	}
+	assert.ErrorIs(t, channel.Err(), failure)
+}
@@ -0,0 +1,111 @@
+// Package sse reads Server-Sent Events streams, as used by LLM streaming APIs
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Event is a single dispatched server-sent event
+type Event struct {
+	// Type is the event type, "message" unless set by an event field
+	Type string
+
+	// Data holds the event's data fields joined by newlines
+	Data string
+
+	// ID is the last event ID seen in the stream
+	ID string
+}
+
+// Reader reads events from a text/event-stream. Comments, such as the
+// keep-alive lines many servers send, and retry fields are skipped.
+type Reader struct {
+	r      *bufio.Reader
+	lastID string
+}
+
+// NewReader returns a Reader reading events from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event. At the end of the stream it returns io.EOF;
+// an event that was not terminated by a blank line is discarded, as the
+// specification demands.
+func (r *Reader) Next() (Event, error) {
+	var (
+		eventType string
+		data      strings.Builder
+		hasData   bool
+	)
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return Event{}, err
+		}
+
+		// A blank line dispatches the event, unless it has no data
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+
+			if eventType == "" {
+				eventType = "message"
+			}
+			return Event{Type: eventType, Data: data.String(), ID: r.lastID}, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		}
+	}
+}
+
+// readLine reads a line terminated by "\n", "\r\n" or "\r", without the
+// terminator. A final line without terminator counts as unterminated and
+// yields io.EOF.
+func (r *Reader) readLine() (string, error) {
+	var line strings.Builder
+
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case '\n':
+			return line.String(), nil
+		case '\r':
+			if next, err := r.r.Peek(1); err == nil && next[0] == '\n' {
+				r.r.ReadByte()
+			}
+			return line.String(), nil
+		default:
+			line.WriteByte(b)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package sse
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads every event from the stream
+func readAll(t *testing.T, stream string) []Event {
+	t.Helper()
+
+	reader := NewReader(strings.NewReader(stream))
+	var events []Event
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return events
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   string
+		expected []Event
+	}{
+		{
+			name:     "Single event",
+			stream:   "data: hello\n\n",
+			expected: []Event{{Type: "message", Data: "hello"}},
+		},
+		{
+			name:   "Multi-line data",
+			stream: "data: first\ndata:second\ndata\n\n",
+			expected: []Event{
+				{Type: "message", Data: "first\nsecond\n"},
+			},
+		},
+		{
+			name:   "Event type and ID",
+			stream: "event: error\nid: 42\ndata: boom\n\ndata: next\n\n",
+			expected: []Event{
+				{Type: "error", Data: "boom", ID: "42"},
+				{Type: "message", Data: "next", ID: "42"},
+			},
+		},
+		{
+			name:     "Comments and retry skipped",
+			stream:   ": keep-alive\n\nretry: 3000\n\n: ping\ndata: x\n\n",
+			expected: []Event{{Type: "message", Data: "x"}},
+		},
+		{
+			name:     "Event type without data is not dispatched",
+			stream:   "event: ping\n\ndata: y\n\n",
+			expected: []Event{{Type: "message", Data: "y"}},
+		},
+		{
+			name:   "CRLF and CR line endings",
+			stream: "data: a\r\n\r\ndata: b\r\rdata: c\n\n",
+			expected: []Event{
+				{Type: "message", Data: "a"},
+				{Type: "message", Data: "b"},
+				{Type: "message", Data: "c"},
+			},
+		},
+		{
+			name:     "Unterminated event discarded",
+			stream:   "data: complete\n\ndata: partial\n",
+			expected: []Event{{Type: "message", Data: "complete"}},
+		},
+		{
+			name:     "Empty stream",
+			stream:   "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, readAll(t, tt.stream))
+		})
+	}
+}
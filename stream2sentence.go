@@ -7,64 +7,72 @@ import (
 // GenerateSentencesConfig holds configuration for the GenerateSentences function
 type GenerateSentencesConfig struct {
 	SentenceSplitterConfig
+
+	// UpstreamErrorPolicy decides what happens to buffered text when a
+	// Source fails
+	UpstreamErrorPolicy UpstreamErrorPolicy
 }
 
 // GenerateSentences generates well-formed sentences from a stream of text chunks
-// This is the main synchronous API function
+// This is the main synchronous API function. The returned channel must be
+// drained, or its goroutine is never released; consumers that may stop early
+// should use GenerateSentencesWithCancel, GenerateSentencesAsync or the
+// SentencesFromSource iterator.
 func GenerateSentences(generator <-chan string, config GenerateSentencesConfig) <-chan string {
+	return GenerateSentencesAsync(context.Background(), generator, config)
+}
+
+// GenerateSentencesWithCancel works like GenerateSentences and also returns
+// a function that stops generation and closes the channel, so a consumer
+// may stop reading early without leaking the generating goroutine. It
+// should be called once the consumer is done, like a context.CancelFunc.
+func GenerateSentencesWithCancel(generator <-chan string, config GenerateSentencesConfig) (<-chan string, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return GenerateSentencesAsync(ctx, generator, config), cancel
+}
+
+// GenerateSentencesAsync generates sentences from an async stream with context support.
+// Cancelling ctx releases the generating goroutine even if the consumer has
+// stopped reading, and closes the returned channel. The channel is buffered
+// and handles a lagging consumer as set by config.Output.
+func GenerateSentencesAsync(ctx context.Context, generator <-chan string, config GenerateSentencesConfig) <-chan string {
 	splitter := NewSentenceSplitter(config.SentenceSplitterConfig)
-	resultChan := make(chan string, 10)
+	resultChan := make(chan string, config.Output.buffer())
+	splitter.measureBacklog(func() int { return len(resultChan) })
 
 	go func() {
 		defer close(resultChan)
+		defer splitter.Close()
 
-		// Process input chunks
-		for chunk := range generator {
-			splitter.Add(chunk)
-			for sentence := range splitter.Stream() {
-				resultChan <- sentence
+		// Sentences are sent straight from the splitter, so no goroutine
+		// is left behind when ctx is cancelled mid-chunk
+		wait := func(text string) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultChan <- text:
+				return nil
 			}
 		}
-
-		// Flush remaining sentences
-		for sentence := range splitter.Flush() {
-			resultChan <- sentence
+		send := func(sentence Sentence) error {
+			return config.Output.deliver(resultChan, sentence.Text, wait, splitter.drop)
 		}
-	}()
 
-	return resultChan
-}
-
-// GenerateSentencesAsync generates sentences from an async stream with context support
-func GenerateSentencesAsync(ctx context.Context, generator <-chan string, config GenerateSentencesConfig) <-chan string {
-	splitter := NewSentenceSplitter(config.SentenceSplitterConfig)
-	resultChan := make(chan string, 10)
-
-	go func() {
-		defer close(resultChan)
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case chunk, ok := <-generator:
 				if !ok {
-					for sentence := range splitter.Flush() {
-						select {
-						case <-ctx.Done():
-							return
-						case resultChan <- sentence:
-						}
-					}
+					// Flush remaining sentences
+					splitter.flush(send)
 					return
 				}
 
+				// A failing callback or cancellation aborts generation
 				splitter.Add(chunk)
-				for sentence := range splitter.Stream() {
-					select {
-					case <-ctx.Done():
-						return
-					case resultChan <- sentence:
-					}
+				if splitter.process(send) != nil {
+					return
 				}
 			}
 		}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper function to create character-by-character generator
@@ -287,6 +288,17 @@ func TestCleanupLinks(t *testing.T) {
 	assert.Contains(t, result, "for more info")
 }
 
+func TestCleanupLinksStreamed(t *testing.T) {
+	config := DefaultConfig()
+	config.CleanupOptions = CleanupLinks
+
+	// Delimiters inside a link do not cut it, and the text after it stays
+	sentences := collectSentences(GenerateSentences(createCharacterGenerator("See https://example.com/a-b, then stop.\nhttp://x.com\nnow"), GenerateSentencesConfig{
+		SentenceSplitterConfig: config,
+	}))
+	assert.Equal(t, "See then stop. now", strings.Join(sentences, " "))
+}
+
 func TestCleanupEmojis(t *testing.T) {
 	text := "Hello 😀 world! This is great 🎉 stuff."
 
@@ -558,6 +570,78 @@ func TestSentenceSplitterReuse(t *testing.T) {
 	assert.True(t, len(secondResults) > 0)
 }
 
+func TestSentenceSplitterReset(t *testing.T) {
+	splitter := NewSentenceSplitter(DefaultConfig())
+
+	splitter.Add("First test sentence. Some unfinished text")
+	drain(splitter, false)
+	splitter.Add("that is still pending")
+
+	splitter.Reset()
+	assert.Empty(t, drain(splitter, true))
+
+	// A reset splitter behaves like a fresh one
+	splitter.Add("Third test. ")
+	splitter.Add("Fourth sentence.")
+
+	fresh := NewSentenceSplitter(DefaultConfig())
+	fresh.Add("Third test. ")
+	fresh.Add("Fourth sentence.")
+
+	assert.Equal(t, drain(fresh, true), drain(splitter, true))
+}
+
+func TestSentenceSplitterResetClearsError(t *testing.T) {
+	config := DefaultConfig()
+	config.Callbacks.OnSentence = func(sentence Sentence) error {
+		if strings.Contains(sentence.Text, "Fail") {
+			return assert.AnError
+		}
+		return nil
+	}
+
+	splitter := NewSentenceSplitter(config)
+	assert.ErrorIs(t, splitter.Feed("Fail right here, please."), assert.AnError)
+
+	splitter.Reset()
+	assert.NoError(t, splitter.Err())
+	assert.NoError(t, splitter.Feed("Works again."))
+	assert.NoError(t, splitter.Finish())
+}
+
+func TestSentenceSplitterReconfigure(t *testing.T) {
+	// Chatty mode yields short fragments quickly
+	chatty := DefaultConfig()
+
+	// Document mode only splits on full sentences
+	document := DefaultConfig()
+	document.QuickYieldMode = NoQuickYield
+	document.SentenceFragmentDelimiters = ".?!"
+	document.FullSentenceDelimiters = ".?!"
+	document.MinimumSentenceLength = 30
+
+	splitter := NewSentenceSplitter(chatty)
+	splitter.Add("Sure thing, let me read that for you")
+	sentences := drain(splitter, false)
+	assert.Equal(t, []string{"Sure thing,"}, sentences)
+
+	// An invalid configuration is rejected and the current one kept
+	invalid := document
+	invalid.ContextSize = -1
+	assert.ErrorIs(t, splitter.Reconfigure(invalid), ErrInvalidConfig)
+	assert.Equal(t, chatty, splitter.tuningConfig())
+
+	require.NoError(t, splitter.Reconfigure(document))
+	splitter.Add(": the quarterly report, which covers sales, costs and outlook, is ready. It was published today.")
+	sentences = append(sentences, drain(splitter, true)...)
+
+	assert.Equal(t, []string{
+		"Sure thing,",
+		"let me read that for you: the quarterly report, which covers sales, costs and outlook, is ready.",
+		"It was published today.",
+	}, sentences)
+}
+
 // === Utility Function Tests ===
 
 func TestAvoidPauseWords(t *testing.T) {
@@ -1,7 +1,12 @@
 package stream2sentence
 
 import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // CleanupFlags represents text cleanup options using bit operations
@@ -18,11 +23,119 @@ const (
 	CleanupBasic = CleanupLinks | CleanupEmojis | StripText
 )
 
+// cleanupFlagNames maps each cleanup flag to its text name
+var cleanupFlagNames = []struct {
+	flag CleanupFlags
+	name string
+}{
+	{CleanupLinks, "links"},
+	{CleanupEmojis, "emojis"},
+	{CleanupTable, "table"},
+	{StripText, "strip"},
+}
+
+// cleanupFlagByName looks up a single cleanup flag by its text name
+func cleanupFlagByName(name string) (CleanupFlags, bool) {
+	for _, flag := range cleanupFlagNames {
+		if flag.name == name {
+			return flag.flag, true
+		}
+	}
+	return 0, false
+}
+
 // HasFlag checks if a specific flag is set
 func (f CleanupFlags) HasFlag(flag CleanupFlags) bool {
 	return f&flag != 0
 }
 
+// String returns the comma-separated flag names, or "none"
+func (f CleanupFlags) String() string {
+	text, err := f.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("CleanupFlags(%d)", int(f))
+	}
+	return string(text)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (f CleanupFlags) MarshalText() ([]byte, error) {
+	if f&^CleanupAll != 0 {
+		return nil, fmt.Errorf("stream2sentence: unknown cleanup flags %#x", int(f&^CleanupAll))
+	}
+
+	if f == 0 {
+		return []byte("none"), nil
+	}
+
+	var names []string
+	for _, flag := range cleanupFlagNames {
+		if f.HasFlag(flag.flag) {
+			names = append(names, flag.name)
+		}
+	}
+	return []byte(strings.Join(names, ",")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts a
+// comma-separated list of flag names as well as "all", "basic" and "none",
+// or the numeric value configurations were written with before flags had
+// names.
+func (f *CleanupFlags) UnmarshalText(text []byte) error {
+	if n, err := strconv.Atoi(strings.TrimSpace(string(text))); err == nil {
+		if n < 0 || CleanupFlags(n)&^CleanupAll != 0 {
+			return fmt.Errorf("stream2sentence: unknown cleanup flags %d", n)
+		}
+		*f = CleanupFlags(n)
+		return nil
+	}
+
+	var flags CleanupFlags
+
+	for _, name := range strings.Split(string(text), ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "none", "":
+		case "all":
+			flags |= CleanupAll
+		case "basic":
+			flags |= CleanupBasic
+		default:
+			flag, ok := cleanupFlagByName(name)
+			if !ok {
+				return fmt.Errorf("stream2sentence: unknown cleanup flag %q", name)
+			}
+			flags |= flag
+		}
+	}
+
+	*f = flags
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts flag names, as
+// UnmarshalText does, or a number.
+func (f *CleanupFlags) UnmarshalJSON(data []byte) error {
+	return unmarshalTextOrNumber(data, f)
+}
+
+// unmarshalTextOrNumber decodes a JSON string or number with the
+// UnmarshalText method of v. Like other types, v is left unchanged by null.
+func unmarshalTextOrNumber(data []byte, v encoding.TextUnmarshaler) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var n json.Number
+		if json.Unmarshal(data, &n) != nil {
+			return err
+		}
+		text = n.String()
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
 // CleanText cleans the text based on the provided cleanup flags
 func CleanText(text string, flags CleanupFlags) string {
 	result := text
@@ -32,16 +145,16 @@ func CleanText(text string, flags CleanupFlags) string {
 		result = stripTableStructures(result)
 	}
 
+	// Handle emoji cleanup, before links so an emoji cannot hide one
+	if flags.HasFlag(CleanupEmojis) {
+		result = stripEmojis(result)
+	}
+
 	// Handle link cleanup
 	if flags.HasFlag(CleanupLinks) {
 		result = stripHTTPURLs(result)
 	}
 
-	// Handle emoji cleanup
-	if flags.HasFlag(CleanupEmojis) {
-		result = stripEmojis(result)
-	}
-
 	// Handle text stripping
 	if flags.HasFlag(StripText) {
 		result = strings.TrimSpace(result)
@@ -50,19 +163,53 @@ func CleanText(text string, flags CleanupFlags) string {
 	return result
 }
 
-// stripHTTPURLs removes simple HTTP/HTTPS URLs using pattern matching
+// stripHTTPURLs removes simple HTTP/HTTPS URLs using pattern matching.
+// Words are delimited by any whitespace; a removed URL takes the whitespace
+// before it along, or the whitespace after it at the start of the text.
 func stripHTTPURLs(text string) string {
 	// Simple pattern to match URLs - not using regex to avoid dependency
-	lines := strings.Split(text, " ")
-	var cleanedWords []string
+	var result strings.Builder
+	rest := text
+	for rest != "" {
+		start := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if start < 0 {
+			result.WriteString(rest)
+			break
+		}
+		end := strings.IndexFunc(rest[start:], unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		} else {
+			end += start
+		}
 
-	for _, word := range lines {
-		if !strings.HasPrefix(word, "http://") && !strings.HasPrefix(word, "https://") {
-			cleanedWords = append(cleanedWords, word)
+		if !isHTTPURL(rest[start:end]) {
+			result.WriteString(rest[:end])
+		} else if result.Len() == 0 && start == 0 {
+			// Drop the whitespace after a leading URL instead
+			rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+			continue
 		}
+		rest = rest[end:]
 	}
 
-	return strings.Join(cleanedWords, " ")
+	return result.String()
+}
+
+// isHTTPURL reports whether word is a link removed by CleanupLinks
+func isHTTPURL(word string) bool {
+	return strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://")
+}
+
+// hasURLScheme reports whether word starts with an HTTP scheme, so it may
+// be a link that is still being streamed
+func hasURLScheme(word string) bool {
+	return strings.HasPrefix(word, "http:") || strings.HasPrefix(word, "https:")
+}
+
+// lastWord returns the text after the last whitespace of text
+func lastWord(text string) string {
+	return text[strings.LastIndexFunc(text, unicode.IsSpace)+1:]
 }
 
 // normalizeNewlines replaces multiple consecutive newlines with double newlines
@@ -0,0 +1,149 @@
+package stream2sentence
+
+import (
+	"context"
+	"sync"
+)
+
+// Interruption reports how far a tracked generation got when it was
+// interrupted
+type Interruption struct {
+	// Spoken holds the sentences the consumer acknowledged
+	Spoken []Sentence
+
+	// InFlight holds the sentences the consumer received but did not
+	// acknowledge, e.g. the one being spoken at the time
+	InFlight []Sentence
+
+	// Remainder is the text that never reached the consumer
+	Remainder
+}
+
+// Generation is a sentence generation whose consumer acknowledges the
+// sentences it has spoken, so an interruption can report exactly what was
+// said. It is created by GenerateSentencesTracked.
+type Generation struct {
+	splitter  *SentenceSplitter
+	sentences chan Sentence
+	stop      chan struct{}
+	finished  chan struct{}
+
+	mu    sync.Mutex
+	sent  []Sentence
+	acked int
+
+	interruptOnce sync.Once
+	interruption  Interruption
+}
+
+// GenerateSentencesTracked generates sentences from an async stream like
+// GenerateSentencesAsync, delivering Sentence values so the consumer can
+// pass them to Ack once they are spoken. Cancelling ctx or calling Interrupt
+// stops the generation and closes the channel returned by Sentences. The
+// channel has config.Output.Buffer slots; the lag policy is not applied, as
+// every sentence must be accounted for.
+func GenerateSentencesTracked(ctx context.Context, generator <-chan string, config GenerateSentencesConfig) *Generation {
+	g := &Generation{
+		splitter:  NewSentenceSplitter(config.SentenceSplitterConfig),
+		sentences: make(chan Sentence, config.Output.buffer()),
+		stop:      make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+
+	// Sentences not yet acknowledged count towards the backlog
+	g.splitter.measureBacklog(func() int {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return len(g.sent) - min(g.acked, len(g.sent))
+	})
+
+	go func() {
+		defer close(g.finished)
+		defer close(g.sentences)
+
+		send := func(sentence Sentence) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-g.stop:
+				return ErrInterrupted
+			case g.sentences <- sentence:
+				g.mu.Lock()
+				g.sent = append(g.sent, sentence)
+				g.mu.Unlock()
+				return nil
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-g.stop:
+				return
+			case chunk, ok := <-generator:
+				if !ok {
+					g.splitter.flush(send)
+					return
+				}
+
+				g.splitter.Add(chunk)
+				if g.splitter.process(send) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return g
+}
+
+// Sentences returns the channel of generated sentences
+func (g *Generation) Sentences() <-chan Sentence {
+	return g.sentences
+}
+
+// ReportQueueDepth reports sentences the consumer has queued beyond those it
+// has not acknowledged yet, see SentenceSplitter.ReportQueueDepth
+func (g *Generation) ReportQueueDepth(depth int) {
+	g.splitter.ReportQueueDepth(depth)
+}
+
+// Ack marks sentence, and all sentences before it, as spoken
+func (g *Generation) Ack(sentence Sentence) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.acked = max(g.acked, sentence.Index+1)
+}
+
+// Interrupt stops the generation and reports which sentences were spoken,
+// which were received but not acknowledged and which text never reached the
+// consumer. Chunks still waiting in the generator channel are not read.
+// Later calls return the same result.
+func (g *Generation) Interrupt() Interruption {
+	g.interruptOnce.Do(func() {
+		close(g.stop)
+		<-g.finished
+
+		var unread []string
+		for sentence := range g.sentences {
+			unread = append(unread, sentence.Text)
+		}
+
+		remainder := g.splitter.Interrupt()
+		remainder.Sentences = append(unread, remainder.Sentences...)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		received := g.sent[:len(g.sent)-len(unread)]
+		acked := min(g.acked, len(received))
+		g.interruption = Interruption{
+			Spoken:    received[:acked],
+			InFlight:  received[acked:],
+			Remainder: remainder,
+		}
+	})
+
+	return g.interruption
+}